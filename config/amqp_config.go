@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -9,45 +11,310 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+const (
+	defaultRmqChannelPoolSize = 8
+	rmqInitialConnectTimeout  = 25 * time.Second
+	rmqBorrowTimeout          = 5 * time.Second
+	rmqMinReconnectDelay      = 1 * time.Second
+	rmqMaxReconnectDelay      = 30 * time.Second
+	rmqReturnBufferSize       = 16
+)
+
+var ErrRMQNotConnected = errors.New("RabbitMQ connection is not ready")
+
+type RMQChannel struct {
+	*amqp.Channel
+	Returns <-chan amqp.Return
+}
+
+func newRMQChannel(conn *amqp.Connection) (*RMQChannel, error) {
+	ch, err := conn.Channel()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to enable confirm mode: %w", err)
+	}
+
+	return &RMQChannel{
+		Channel: ch,
+		Returns: ch.NotifyReturn(make(chan amqp.Return, rmqReturnBufferSize)),
+	}, nil
+}
+
 type RMQInstance struct {
-	Conn    *amqp.Connection
-	Channel *amqp.Channel
+	name      string
+	url       string
+	mu        sync.RWMutex
+	conn      *amqp.Connection
+	state     ConnectionState
+	idle      chan *RMQChannel
+	slots     chan struct{}
+	connected chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 var rmqInstances = make(map[string]*RMQInstance)
 var mu sync.Mutex
 
-func initializeRabbitMQ(url, rmqInstance string) error {
-	mu.Lock()
-	defer mu.Unlock()
+func newRMQInstance(url, name string, poolSize int) *RMQInstance {
+	return &RMQInstance{
+		name:      name,
+		url:       url,
+		state:     STATE_CONNECTING,
+		idle:      make(chan *RMQChannel, poolSize),
+		slots:     make(chan struct{}, poolSize),
+		connected: make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
 
-	var conn *amqp.Connection
-	var ch *amqp.Channel
-	var err error
+func (r *RMQInstance) Name() string {
+	return r.name
+}
+
+func (r *RMQInstance) State() ConnectionState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.state
+}
+
+func (r *RMQInstance) IsConnected() bool {
+	return r.State() == STATE_CONNECTED
+}
+
+func (r *RMQInstance) setState(state ConnectionState) {
+	r.mu.Lock()
+	changed := r.state != state
+	r.state = state
+	r.mu.Unlock()
+
+	if changed {
+		notifyStateChange("rmq", r.name, state)
+	}
+}
+
+func (r *RMQInstance) supervise() {
+	delay := rmqMinReconnectDelay
+	first := true
+
+	for {
+		select {
+		case <-r.done:
+			return
+		default:
+		}
+
+		r.setState(STATE_CONNECTING)
 
-	for i := range 5 {
-		conn, err = amqp.Dial(url)
+		conn, err := amqp.Dial(r.url)
 
-		if err == nil {
-			log.Infof("✅ Successfully connected to RabbitMQ (%s)", rmqInstance)
+		if err != nil {
+			r.setState(STATE_DISCONNECTED)
+			log.Warnf("⚠️ Failed to connect to RabbitMQ (%s), retrying in %s: %v", r.name, delay, err)
 
-			ch, err = conn.Channel()
-			if err != nil {
-				log.Infof("❌ Failed to open channel: %v", err)
-				return err
+			select {
+			case <-r.done:
+				return
+			case <-time.After(delay):
 			}
 
-			rmqInstances[rmqInstance] = &RMQInstance{Conn: conn, Channel: ch}
+			delay = min(delay*2, rmqMaxReconnectDelay)
+			continue
+		}
+
+		delay = rmqMinReconnectDelay
+		closed := conn.NotifyClose(make(chan *amqp.Error, 1))
 
-			log.Info("✅ RabbitMQ channel opened successfully 🚀")
-			return nil
+		r.mu.Lock()
+		r.conn = conn
+		r.mu.Unlock()
+
+		r.fillPool(conn)
+		r.setState(STATE_CONNECTED)
+
+		log.Infof("✅ Successfully connected to RabbitMQ (%s) 🚀", r.name)
+
+		if first {
+			close(r.connected)
+			first = false
+		}
+
+		select {
+		case <-r.done:
+			return
+		case amqpErr := <-closed:
+			log.Errorf("⚠️ RabbitMQ %s connection closed: %v", r.name, amqpErr)
 		}
 
-		log.Infof("⚠️ Failed to connect to RabbitMQ, retrying in 5 seconds... (%d/5)", i+1)
-		time.Sleep(5 * time.Second)
+		r.mu.Lock()
+		r.conn = nil
+		r.mu.Unlock()
+
+		r.drainIdle()
+		r.setState(STATE_DISCONNECTED)
 	}
+}
+
+func (r *RMQInstance) fillPool(conn *amqp.Connection) {
+	r.drainIdle()
 
-	return err
+	for range cap(r.idle) - len(r.slots) {
+		ch, err := newRMQChannel(conn)
+
+		if err != nil {
+			log.Errorf("❌ Failed to open channel on %s: %v", r.name, err)
+			return
+		}
+
+		select {
+		case r.idle <- ch:
+		default:
+			ch.Close()
+			return
+		}
+	}
+}
+
+func (r *RMQInstance) drainIdle() {
+	for {
+		select {
+		case ch := <-r.idle:
+			if !ch.IsClosed() {
+				ch.Close()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (r *RMQInstance) openChannel() (*RMQChannel, error) {
+	r.mu.RLock()
+	conn := r.conn
+	r.mu.RUnlock()
+
+	if conn == nil || conn.IsClosed() {
+		return nil, ErrRMQNotConnected
+	}
+
+	return newRMQChannel(conn)
+}
+
+func (r *RMQInstance) Borrow() (*RMQChannel, error) {
+	if !r.IsConnected() {
+		return nil, fmt.Errorf("%s: %w", r.name, ErrRMQNotConnected)
+	}
+
+	select {
+	case r.slots <- struct{}{}:
+	case <-time.After(rmqBorrowTimeout):
+		return nil, fmt.Errorf("timed out waiting for a free RabbitMQ channel on %s", r.name)
+	}
+
+	for {
+		select {
+		case ch := <-r.idle:
+			if ch.IsClosed() {
+				continue
+			}
+
+			return ch, nil
+		default:
+		}
+
+		ch, err := r.openChannel()
+
+		if err != nil {
+			<-r.slots
+			return nil, fmt.Errorf("failed to open channel on %s: %w", r.name, err)
+		}
+
+		return ch, nil
+	}
+}
+
+func (r *RMQInstance) Return(ch *RMQChannel) {
+	defer func() { <-r.slots }()
+
+	if ch == nil || ch.IsClosed() {
+		return
+	}
+
+	select {
+	case r.idle <- ch:
+	default:
+		ch.Close()
+	}
+}
+
+func (r *RMQInstance) close() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+	})
+
+	r.drainIdle()
+
+	r.mu.Lock()
+	conn := r.conn
+	r.conn = nil
+	r.mu.Unlock()
+
+	if conn != nil && !conn.IsClosed() {
+		conn.Close()
+	}
+
+	r.setState(STATE_CLOSED)
+}
+
+func rmqChannelPoolSize() int {
+	size, err := strconv.Atoi(RMQ_CHANNEL_POOL_SIZE.GetValue())
+
+	if err != nil || size <= 0 {
+		return defaultRmqChannelPoolSize
+	}
+
+	return size
+}
+
+func startRabbitMQ(url, rmqInstance string) (*RMQInstance, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := rmqInstances[rmqInstance]; ok {
+		return nil, fmt.Errorf("RabbitMQ instance %s already initialized", rmqInstance)
+	}
+
+	instance := newRMQInstance(url, rmqInstance, rmqChannelPoolSize())
+	rmqInstances[rmqInstance] = instance
+
+	go instance.supervise()
+
+	return instance, nil
+}
+
+func initializeRabbitMQ(url, rmqInstance string, wait bool) error {
+	instance, err := startRabbitMQ(url, rmqInstance)
+
+	if err != nil {
+		return err
+	}
+
+	if !wait {
+		return nil
+	}
+
+	select {
+	case <-instance.connected:
+		return nil
+	case <-time.After(rmqInitialConnectTimeout):
+		return fmt.Errorf("RabbitMQ instance %s could not connect within %s", rmqInstance, rmqInitialConnectTimeout)
+	}
 }
 
 func GetRMQInstance(rmqtype string) (*RMQInstance, error) {
@@ -63,26 +330,57 @@ func GetRMQInstance(rmqtype string) (*RMQInstance, error) {
 	return instance, nil
 }
 
-func CloseRabbitMQ() {
+func GetRMQStates() map[string]ConnectionState {
 	mu.Lock()
 	defer mu.Unlock()
 
+	states := make(map[string]ConnectionState, len(rmqInstances))
+
+	for name, instance := range rmqInstances {
+		states[name] = instance.State()
+	}
+
+	return states
+}
+
+func CloseRabbitMQ() {
+	mu.Lock()
+	instances := make(map[string]*RMQInstance, len(rmqInstances))
+
 	for name, rmqInstance := range rmqInstances {
-		if rmqInstance.Channel != nil {
-			rmqInstance.Channel.Close()
-			log.Infof("🔒 RabbitMQ %s channel closed \n", name)
-		}
+		instances[name] = rmqInstance
+	}
+	mu.Unlock()
 
-		if rmqInstance.Conn != nil {
-			rmqInstance.Conn.Close()
-			log.Infof("🔒 RabbitMQ %s connection closed", name)
-		}
+	for name, rmqInstance := range instances {
+		rmqInstance.close()
+		log.Infof("🔒 RabbitMQ %s connection closed", name)
 	}
 }
 
 func CreateRmqInstance() {
+	watchCloudAttachment()
+
+	// Init RabbitMQ Local
+	if IsLocalRmqConfigured() {
+		if err := initializeRabbitMQ(RMQ_LOCAL_URI.GetValue(), RMQ_LOCAL_INSTANCE.GetValue(), true); err != nil {
+			log.Errorf("❌ %v, local consumers will start once it is reachable", err)
+		}
+	} else {
+		log.Warn("⚠️ RabbitMQ local is not configured, local rules and registration queues are disabled")
+	}
+
 	// Init RabbitMQ Cloud
-	if err := initializeRabbitMQ(RMQ_CLOUD_URI.GetValue(), RMQ_CLOUD_INSTANCE.GetValue()); err != nil {
-		log.Fatal(err)
+	if !IsCloudRmqConfigured() {
+		log.Warn("⚠️ RabbitMQ cloud is not configured, running offline and queueing cloud messages")
+		return
+	}
+
+	if IsOfflineMode() {
+		log.Warn("⚠️ Offline mode enabled, RabbitMQ cloud will be attached once reachable")
+	}
+
+	if err := initializeRabbitMQ(RMQ_CLOUD_URI.GetValue(), RMQ_CLOUD_INSTANCE.GetValue(), !IsOfflineMode()); err != nil {
+		log.Warnf("⚠️ %v, continuing offline until the cloud is reachable", err)
 	}
 }
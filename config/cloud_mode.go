@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+func IsOfflineMode() bool {
+	return strings.EqualFold(OFFLINE_MODE.GetValue(), "true")
+}
+
+func IsLocalRmqConfigured() bool {
+	return RMQ_LOCAL_URI.GetValue() != ""
+}
+
+func IsCloudRmqConfigured() bool {
+	return RMQ_CLOUD_URI.GetValue() != ""
+}
+
+func IsCloudMqttConfigured() bool {
+	return MQTT_CLOUD_HOST.GetValue() != ""
+}
+
+func IsCloudOnline() bool {
+	instance, err := GetRMQInstance(RMQ_CLOUD_INSTANCE.GetValue())
+
+	return err == nil && instance.IsConnected()
+}
+
+func watchCloudAttachment() {
+	OnConnectionStateChange(func(kind, instanceName string, state ConnectionState) {
+		if instanceName != RMQ_CLOUD_INSTANCE.GetValue() && instanceName != MQTT_CLOUD_INSTANCE_NAME.GetValue() {
+			return
+		}
+
+		switch state {
+		case STATE_CONNECTED:
+			log.Infof("☁️ Cloud %s %s attached", kind, instanceName)
+		case STATE_DISCONNECTED:
+			log.Warnf("☁️ Cloud %s %s detached, cloud messages are queued locally", kind, instanceName)
+		}
+	})
+}
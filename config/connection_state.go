@@ -0,0 +1,34 @@
+package config
+
+import "sync"
+
+type ConnectionState string
+
+const (
+	STATE_CONNECTING   ConnectionState = "CONNECTING"
+	STATE_CONNECTED    ConnectionState = "CONNECTED"
+	STATE_DISCONNECTED ConnectionState = "DISCONNECTED"
+	STATE_CLOSED       ConnectionState = "CLOSED"
+)
+
+type StateListener func(kind, instanceName string, state ConnectionState)
+
+var stateListeners []StateListener
+var stateMu sync.RWMutex
+
+func OnConnectionStateChange(listener StateListener) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	stateListeners = append(stateListeners, listener)
+}
+
+func notifyStateChange(kind, instanceName string, state ConnectionState) {
+	stateMu.RLock()
+	listeners := append([]StateListener(nil), stateListeners...)
+	stateMu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(kind, instanceName, state)
+	}
+}
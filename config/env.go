@@ -13,10 +13,29 @@ const (
 	DB_PATH     EnvKey = "DB_PATH"
 	MAC_ADDRESS EnvKey = "MAC_ADDRESS"
 
+	// Offline Mode
+	OFFLINE_MODE EnvKey = "OFFLINE_MODE"
+
+	// Message Deduplication
+	DEDUP_WINDOW EnvKey = "DEDUP_WINDOW"
+
+	// Aktuator Command Acknowledgement
+	AKTUATOR_ACK_TIMEOUT  EnvKey = "AKTUATOR_ACK_TIMEOUT"
+	AKTUATOR_ACK_REQUIRED EnvKey = "AKTUATOR_ACK_REQUIRED"
+
 	// Exchange Broker
 	EXCHANGE_DIRECT EnvKey = "EXCHANGE_DIRECT"
 	EXCHANGE_TOPIC  EnvKey = "EXCHANGE_TOPIC"
 
+	// RabbitMQ
+	RMQ_CHANNEL_POOL_SIZE EnvKey = "RMQ_CHANNEL_POOL_SIZE"
+
+	// RMQ Local
+	RMQ_LOCAL_URI                  EnvKey = "RMQ_URI"
+	RMQ_LOCAL_INSTANCE             EnvKey = "RMQ_HIOTO_LOCAL_INSTANCE"
+	RULES_ROUTING_KEY              EnvKey = "RULES_ROUTING_KEY"
+	REGISTRATION_LOCAL_ROUTING_KEY EnvKey = "REGISTRATION_LOCAL_ROUTING_KEY"
+
 	// RMQ Cloud
 	RMQ_CLOUD_URI             EnvKey = "RMQ_HIOTO"
 	RMQ_CLOUD_INSTANCE        EnvKey = "RMQ_HIOTO_CLOUD_INSTANCE"
@@ -28,6 +47,29 @@ const (
 	UPDATE_DEVICE_ROUTING_KEY EnvKey = "UPDATE_DEVICE_ROUTING_KEY"
 	DELETE_DEVICE_ROUTING_KEY EnvKey = "DELETE_DEVICE_ROUTING_KEY"
 	RULES_RESPONSE_QUEUE      EnvKey = "RULES_RESPONSE_QUEUE"
+	RULES_REQUEST_ROUTING_KEY EnvKey = "RULES_REQUEST_ROUTING_KEY"
+	RULES_DELETE_ROUTING_KEY  EnvKey = "RULES_DELETE_ROUTING_KEY"
+	RULES_RESULT_QUEUE        EnvKey = "RULES_RESULT_QUEUE"
+	FLOOR_RES_CLOUD           EnvKey = "FLOOR_RES_CLOUD"
+	ROOM_RES_CLOUD            EnvKey = "ROOM_RES_CLOUD"
+	FLOOR_ROUTING_KEY         EnvKey = "FLOOR_ROUTING_KEY"
+	ROOM_ROUTING_KEY          EnvKey = "ROOM_ROUTING_KEY"
+	LOG_EXPORT_QUEUE          EnvKey = "LOG_EXPORT_QUEUE"
+	LOG_EXPORT_INTERVAL       EnvKey = "LOG_EXPORT_INTERVAL"
+	LOG_EXPORT_BATCH_SIZE     EnvKey = "LOG_EXPORT_BATCH_SIZE"
+	HEARTBEAT_TOPIC           EnvKey = "HEARTBEAT_TOPIC"
+	HEARTBEAT_INTERVAL        EnvKey = "HEARTBEAT_INTERVAL"
+	PRESENCE_RES_CLOUD        EnvKey = "PRESENCE_RES_CLOUD"
+	PRESENCE_DEFAULT_TIMEOUT  EnvKey = "PRESENCE_DEFAULT_TIMEOUT"
+	PRESENCE_TIMEOUTS         EnvKey = "PRESENCE_TIMEOUTS"
+	FLOOR_CONTROL_ROUTING_KEY EnvKey = "FLOOR_CONTROL_ROUTING_KEY"
+	ROOM_CONTROL_ROUTING_KEY  EnvKey = "ROOM_CONTROL_ROUTING_KEY"
+	BULK_CONTROL_RESULT_QUEUE EnvKey = "BULK_CONTROL_RESULT_QUEUE"
+	BULK_CONTROL_INTERVAL     EnvKey = "BULK_CONTROL_INTERVAL"
+	SYNC_ROUTING_KEY          EnvKey = "SYNC_ROUTING_KEY"
+	SYNC_SNAPSHOT_QUEUE       EnvKey = "SYNC_SNAPSHOT_QUEUE"
+	SYNC_REPORT_QUEUE         EnvKey = "SYNC_REPORT_QUEUE"
+	SYNC_POLICY               EnvKey = "SYNC_POLICY"
 
 	// MQTT Local
 	MQTT_LOCAL_HOST           EnvKey = "MQTT_LOCAL_HOST"
@@ -40,6 +82,21 @@ const (
 	AKTUATOR_TOPIC            EnvKey = "AKTUATOR_TOPIC"
 	MONITORING_TOPIC          EnvKey = "MONITORING_TOPIC"
 
+	// MQTT Per Device Topics
+	MQTT_DEVICE_TOPIC_PREFIX EnvKey = "MQTT_DEVICE_TOPIC_PREFIX"
+
+	// MQTT CBOR Topics
+	MQTT_CBOR_TOPICS EnvKey = "MQTT_CBOR_TOPICS"
+
+	// Home Assistant Discovery
+	HA_DISCOVERY_ENABLED EnvKey = "HA_DISCOVERY_ENABLED"
+	HA_DISCOVERY_PREFIX  EnvKey = "HA_DISCOVERY_PREFIX"
+
+	// MQTT Worker Pool
+	MQTT_WORKER_COUNT      EnvKey = "MQTT_WORKER_COUNT"
+	MQTT_WORKER_QUEUE_SIZE EnvKey = "MQTT_WORKER_QUEUE_SIZE"
+	MQTT_OVERFLOW_POLICY   EnvKey = "MQTT_OVERFLOW_POLICY"
+
 	// MQTT Cloud
 	MQTT_CLOUD_HOST          EnvKey = "MQTT_CLOUD_HOST"
 	MQTT_CLOUD_USERNAME      EnvKey = "MQTT_CLOUD_USERNAME"
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	HEARTBEAT_ONLINE  = "online"
+	HEARTBEAT_OFFLINE = "offline"
+
+	defaultHeartbeatTopic = "Heartbeat"
+)
+
+var Version = "dev"
+
+func HeartbeatTopic() string {
+	topic := HEARTBEAT_TOPIC.GetValue()
+
+	if topic == "" {
+		topic = defaultHeartbeatTopic
+	}
+
+	return fmt.Sprintf("%s/%s", topic, MAC_ADDRESS.GetValue())
+}
+
+func OfflineHeartbeat() []byte {
+	body, _ := json.Marshal(map[string]string{
+		"mac_server": MAC_ADDRESS.GetValue(),
+		"status":     HEARTBEAT_OFFLINE,
+		"version":    Version,
+	})
+
+	return body
+}
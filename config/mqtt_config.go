@@ -9,10 +9,13 @@ import (
 	"github.com/gofiber/fiber/v2/log"
 )
 
-var MqttSubscriptions sync.Map
+const mqttOptionalConnectTimeout = 10 * time.Second
+
+const MQTT_SUBSCRIBE_QOS byte = 1
 
 type MqttInstance struct {
-	client mqtt.Client
+	client        mqtt.Client
+	subscriptions sync.Map
 }
 
 type MqttConfig struct {
@@ -21,14 +24,16 @@ type MqttConfig struct {
 	Username     string
 	Password     string
 	ClientId     string
+	Optional     bool
+	WillTopic    string
+	WillPayload  []byte
 }
 
 var mqttInstance = make(map[string]*MqttInstance)
 var mqttMu sync.Mutex
 
 func initializeMqtt(mqttConfig *MqttConfig) error {
-	mqttMu.Lock()
-	defer mqttMu.Unlock()
+	instance := &MqttInstance{}
 
 	opts := mqtt.NewClientOptions().
 		AddBroker(mqttConfig.Host).
@@ -37,15 +42,20 @@ func initializeMqtt(mqttConfig *MqttConfig) error {
 		SetClientID(mqttConfig.ClientId).
 		SetAutoReconnect(true).
 		SetConnectRetry(true).
-		SetConnectRetryInterval(1 * time.Second)
+		SetConnectRetryInterval(1 * time.Second).
+		SetAutoAckDisabled(true)
+
+	if mqttConfig.WillTopic != "" {
+		opts.SetBinaryWill(mqttConfig.WillTopic, mqttConfig.WillPayload, 1, true)
+	}
 
 	opts.OnConnect = func(client mqtt.Client) {
 		log.Infof("🔓 MQTT %s connected", mqttConfig.InstanceName)
 
-		MqttSubscriptions.Range(func(key, _ any) bool {
+		instance.subscriptions.Range(func(key, _ any) bool {
 			topic := key.(string)
 
-			if token := client.Subscribe(topic, 0, nil); token.Wait() && token.Error() != nil {
+			if token := client.Subscribe(topic, MQTT_SUBSCRIBE_QOS, nil); token.Wait() && token.Error() != nil {
 				log.Errorf("❌ Failed to subscribe '%s': %v", topic, token.Error())
 			} else {
 				log.Infof("🔄 Subscribed (reconnect): %s", topic)
@@ -53,20 +63,44 @@ func initializeMqtt(mqttConfig *MqttConfig) error {
 
 			return true
 		})
+
+		notifyStateChange("mqtt", mqttConfig.InstanceName, STATE_CONNECTED)
 	}
 
 	opts.OnConnectionLost = func(client mqtt.Client, err error) {
 		log.Errorf("⚠️ MQTT %s connection lost: %v", mqttConfig.InstanceName, err)
+		notifyStateChange("mqtt", mqttConfig.InstanceName, STATE_DISCONNECTED)
+	}
+
+	opts.OnReconnecting = func(client mqtt.Client, _ *mqtt.ClientOptions) {
+		notifyStateChange("mqtt", mqttConfig.InstanceName, STATE_CONNECTING)
 	}
 
 	client := mqtt.NewClient(opts)
+	instance.client = client
 
-	if token := client.Connect(); token.Wait() && token.Error() != nil {
-		return fmt.Errorf("[%s] Failed to connect: %v", mqttConfig.InstanceName, token.Error())
+	mqttMu.Lock()
+	mqttInstance[mqttConfig.InstanceName] = instance
+	mqttMu.Unlock()
+
+	token := client.Connect()
+
+	if mqttConfig.Optional {
+		if IsOfflineMode() {
+			log.Warnf("⚠️ MQTT %s will connect in background (offline mode)", mqttConfig.InstanceName)
+			return nil
+		}
+
+		if !token.WaitTimeout(mqttOptionalConnectTimeout) {
+			log.Warnf("⚠️ MQTT %s not reachable yet, retrying in background", mqttConfig.InstanceName)
+			return nil
+		}
+	} else {
+		token.Wait()
 	}
 
-	mqttInstance[mqttConfig.InstanceName] = &MqttInstance{
-		client: client,
+	if token.Error() != nil {
+		return fmt.Errorf("[%s] Failed to connect: %v", mqttConfig.InstanceName, token.Error())
 	}
 
 	return nil
@@ -84,27 +118,63 @@ func GetMqttInstance(instanceName string) (mqtt.Client, error) {
 	return instance.client, nil
 }
 
-func CloseAllMqttInstances() {
+func AddMqttSubscription(instanceName, topic string) error {
+	mqttMu.Lock()
+	defer mqttMu.Unlock()
+
+	instance, ok := mqttInstance[instanceName]
+	if !ok {
+		return fmt.Errorf("MQTT instance %s not found", instanceName)
+	}
+
+	instance.subscriptions.Store(topic, struct{}{})
+
+	return nil
+}
+
+func GetMqttStates() map[string]ConnectionState {
 	mqttMu.Lock()
 	defer mqttMu.Unlock()
 
+	states := make(map[string]ConnectionState, len(mqttInstance))
+
 	for name, instance := range mqttInstance {
 		if instance.client.IsConnectionOpen() {
-			instance.client.Disconnect(250)
-			log.Infof("🔒 MQTT %s closed", name)
+			states[name] = STATE_CONNECTED
+		} else {
+			states[name] = STATE_DISCONNECTED
 		}
 	}
+
+	return states
+}
+
+func CloseAllMqttInstances() {
+	mqttMu.Lock()
+	defer mqttMu.Unlock()
+
+	for name, instance := range mqttInstance {
+		instance.client.Disconnect(250)
+		log.Infof("🔒 MQTT %s closed", name)
+	}
 }
 
 func CreateMqttInstance() {
-	if err := initializeMqtt(&MqttConfig{
-		InstanceName: MQTT_CLOUD_INSTANCE_NAME.GetValue(),
-		Host:         MQTT_CLOUD_HOST.GetValue(),
-		Username:     MQTT_CLOUD_USERNAME.GetValue(),
-		Password:     MQTT_CLOUD_PASSWORD.GetValue(),
-		ClientId:     MQTT_CLOUD_CLIENT_ID.GetValue(),
-	}); err != nil {
-		log.Error(err)
+	if IsCloudMqttConfigured() {
+		if err := initializeMqtt(&MqttConfig{
+			InstanceName: MQTT_CLOUD_INSTANCE_NAME.GetValue(),
+			Host:         MQTT_CLOUD_HOST.GetValue(),
+			Username:     MQTT_CLOUD_USERNAME.GetValue(),
+			Password:     MQTT_CLOUD_PASSWORD.GetValue(),
+			ClientId:     MQTT_CLOUD_CLIENT_ID.GetValue(),
+			Optional:     true,
+			WillTopic:    HeartbeatTopic(),
+			WillPayload:  OfflineHeartbeat(),
+		}); err != nil {
+			log.Error(err)
+		}
+	} else {
+		log.Warn("⚠️ MQTT cloud is not configured, cloud commands are disabled")
 	}
 
 	if err := initializeMqtt(&MqttConfig{
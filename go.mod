@@ -4,7 +4,12 @@ go 1.24.0
 
 toolchain go1.24.7
 
-require github.com/go-playground/validator/v10 v10.27.0
+require (
+	github.com/fxamacker/cbor/v2 v2.9.4
+	github.com/go-playground/validator/v10 v10.27.0
+)
+
+require github.com/x448/float16 v0.8.4 // indirect
 
 require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
@@ -28,7 +33,7 @@ require (
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/gofiber/fiber/v2 v2.52.9
-	github.com/google/uuid v1.6.0 // indirect
+	github.com/google/uuid v1.6.0
 	github.com/joho/godotenv v1.5.1
 	github.com/klauspost/compress v1.17.9 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
@@ -5,6 +5,7 @@ import (
 	"go/hioto/config"
 	"go/hioto/pkg/handler/consumer"
 	"go/hioto/pkg/handler/err"
+	messagebroker "go/hioto/pkg/handler/message_broker"
 	"go/hioto/pkg/router"
 	"go/hioto/pkg/service"
 	"go/hioto/pkg/utils"
@@ -42,16 +43,47 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// define instance brokers
+	cloudRmqBroker := messagebroker.NewAmqpBroker(config.RMQ_CLOUD_INSTANCE.GetValue(), config.EXCHANGE_DIRECT.GetValue())
+	cloudMqttBroker := messagebroker.NewMqttBroker(config.MQTT_CLOUD_INSTANCE_NAME.GetValue())
+	localMqttBroker := messagebroker.NewMqttBroker(config.MQTT_LOCAL_INSTANCE_NAME.GetValue())
+	localRmqBroker := messagebroker.NewAmqpBroker(config.RMQ_LOCAL_INSTANCE.GetValue(), config.EXCHANGE_DIRECT.GetValue())
+
 	// define instance services
-	controlDeviceService := service.NewControlDeviceService(db)
-	deviceService := service.NewDeviceService(db)
-	ruleService := service.NewRuleService(db)
-	floorService := service.NewFloorService(db)
-	roomService := service.NewRoomService(db)
+	outboxService := service.NewOutboxService(db, cloudRmqBroker)
+	quarantineService := service.NewQuarantineService(db)
+	idempotencyService := service.NewIdempotencyService(db)
+	commandService := service.NewCommandService(db, localMqttBroker)
+	deviceEventBus := service.NewDeviceEventBus()
+	controlDeviceService := service.NewControlDeviceService(db, commandService, outboxService, deviceEventBus)
+	groupService := service.NewGroupService(db, controlDeviceService)
+	deviceService := service.NewDeviceService(db, outboxService, deviceEventBus)
+	homeAssistantService := service.NewHomeAssistantService(db, localMqttBroker, controlDeviceService, deviceEventBus)
+	syncService := service.NewSyncService(db, deviceService, outboxService)
+	ruleService := service.NewRuleService(db, outboxService)
+	floorService := service.NewFloorService(db, outboxService, controlDeviceService)
+	roomService := service.NewRoomService(db, outboxService, controlDeviceService)
+	logExportService := service.NewLogExportService(db, cloudRmqBroker)
+	heartbeatService := service.NewHeartbeatService(db, cloudMqttBroker)
+	presenceService := service.NewPresenceService(db, outboxService, deviceEventBus)
+	telemetryService := service.NewTelemetryService(db)
+
+	scheduler := service.NewScheduler()
+	logExportService.Schedule(scheduler)
+	heartbeatService.Schedule(scheduler)
+	presenceService.Schedule(scheduler)
+	presenceService.TrackCommands(commandService)
+
+	go outboxService.StartDispatcher(ctx)
+	go idempotencyService.StartPruner(ctx)
+	go commandService.StartSweeper(ctx)
+	go homeAssistantService.StartDiscovery(ctx)
+	go syncService.StartReconciler(ctx)
+	scheduler.Start(ctx)
 
 	// Start Consumer
-	consumerHandler := consumer.NewConsumerHandler(ruleService, deviceService, controlDeviceService)
-	consumerRouter := router.NewConsumerMessageBroker(ctx, consumerHandler)
+	consumerHandler := consumer.NewConsumerHandler(ruleService, deviceService, controlDeviceService, commandService, idempotencyService, syncService, floorService, roomService, presenceService)
+	consumerRouter := router.NewConsumerMessageBroker(ctx, consumerHandler, quarantineService, homeAssistantService, cloudMqttBroker, localMqttBroker, localRmqBroker)
 	consumerRouter.StartConsumer()
 
 	log.Info("Hello From Hioto Worker 💡")
@@ -82,7 +114,7 @@ func main() {
 	route.Get("/metrics", monitor.New(monitor.Config{Title: "Hioto Metrics Pages"}))
 
 	// REST API Router Group
-	router.Router(route, db, controlDeviceService, deviceService, ruleService, floorService, roomService)
+	router.Router(route, db, controlDeviceService, commandService, deviceService, ruleService, floorService, roomService, outboxService, quarantineService, syncService, logExportService, heartbeatService, telemetryService, groupService)
 
 	log.Infof("API server is running on http://localhost:%s/api 💡", port)
 
@@ -95,6 +127,8 @@ func main() {
 	<-ctx.Done()
 	log.Warn("Shutting down gracefully...💡")
 
+	heartbeatService.PublishOffline()
+
 	if err := app.Shutdown(); err != nil {
 		log.Errorf("Error shutting down Fiber: %v", err)
 	}
@@ -0,0 +1,72 @@
+package codec
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+type cborTelemetry struct {
+	Guid   string            `cbor:"guid"`
+	Ts     any               `cbor:"ts"`
+	Values map[string]any    `cbor:"values"`
+	Units  map[string]string `cbor:"units"`
+}
+
+type CborCodec struct{}
+
+func (CborCodec) Name() string {
+	return "cbor"
+}
+
+func (CborCodec) Detect(payload []byte) bool {
+	return startsLikeCborMap(payload)
+}
+
+func (CborCodec) Decode(payload []byte, guid string) (*Telemetry, error) {
+	var document cborTelemetry
+
+	if err := cbor.Unmarshal(payload, &document); err != nil {
+		return nil, fmt.Errorf("invalid cbor telemetry: %w", err)
+	}
+
+	timestamp, err := parseCborTimestamp(document.Ts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTelemetry(document.Guid, guid, timestamp, document.Values, document.Units)
+}
+
+func startsLikeCborMap(payload []byte) bool {
+	return len(payload) > 0 && payload[0]>>5 == 5
+}
+
+func parseCborTimestamp(value any) (*time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return &v, nil
+	case cbor.Tag:
+		if v.Number != 1 {
+			return nil, fmt.Errorf("unsupported telemetry timestamp tag %d", v.Number)
+		}
+
+		return parseCborTimestamp(v.Content)
+	case string:
+		return parseTimestamp(v)
+	case uint64:
+		return unixTimestamp(float64(v)), nil
+	case int64:
+		return unixTimestamp(float64(v)), nil
+	case float64:
+		return unixTimestamp(v), nil
+	case float32:
+		return unixTimestamp(float64(v)), nil
+	default:
+		return nil, fmt.Errorf("unsupported telemetry timestamp type %T", value)
+	}
+}
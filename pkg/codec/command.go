@@ -0,0 +1,104 @@
+package codec
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+type Command struct {
+	Guid          string `cbor:"guid,omitempty"`
+	Channel       int    `cbor:"ch,omitempty"`
+	Value         string `cbor:"value"`
+	CorrelationID string `cbor:"id,omitempty"`
+	Status        string `cbor:"status,omitempty"`
+}
+
+func EncodeCommand(name string, command *Command, withGuid bool) ([]byte, error) {
+	if name == CODEC_CBOR {
+		payload := *command
+		payload.Guid = ""
+
+		body, err := cbor.Marshal(payload)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cbor command: %w", err)
+		}
+
+		if withGuid {
+			return append([]byte(command.Guid+"#"), body...), nil
+		}
+
+		return body, nil
+	}
+
+	value := command.Value
+
+	if command.Channel > 0 {
+		value = strconv.Itoa(command.Channel) + ":" + value
+	}
+
+	parts := []string{value}
+
+	if command.CorrelationID != "" {
+		parts = append(parts, command.CorrelationID)
+	}
+
+	if withGuid {
+		parts = append([]string{command.Guid}, parts...)
+	}
+
+	if command.Status != "" {
+		parts = append(parts, command.Status)
+	}
+
+	return []byte(strings.Join(parts, "#")), nil
+}
+
+func DecodeCommand(payload []byte) (*Command, error) {
+	guid, body := SplitGuid(payload)
+
+	if startsLikeCborMap(body) {
+		var command Command
+
+		if err := cbor.Unmarshal(body, &command); err != nil {
+			return nil, fmt.Errorf("invalid cbor command: %w", err)
+		}
+
+		if command.Guid == "" {
+			command.Guid = guid
+		}
+
+		return &command, nil
+	}
+
+	parts := strings.Split(string(bytes.TrimSpace(payload)), "#")
+
+	var command *Command
+
+	switch len(parts) {
+	case 2:
+		command = &Command{Guid: parts[0], Value: parts[1]}
+	case 3:
+		command = &Command{Guid: parts[0], Value: parts[1], CorrelationID: parts[2]}
+	case 4:
+		command = &Command{Guid: parts[0], Value: parts[1], CorrelationID: parts[2], Status: parts[3]}
+	default:
+		return nil, fmt.Errorf("invalid command %q, expected guid#value#id", string(payload))
+	}
+
+	if channel, value, found := strings.Cut(command.Value, ":"); found {
+		number, err := strconv.Atoi(channel)
+
+		if err != nil || number < 1 {
+			return nil, fmt.Errorf("invalid channel %q in command %q", channel, string(payload))
+		}
+
+		command.Channel, command.Value = number, value
+	}
+
+	return command, nil
+}
@@ -0,0 +1,138 @@
+package codec
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+func TestEncodeCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		codec    string
+		command  Command
+		withGuid bool
+		want     string
+	}{
+		{
+			name:     "plain value",
+			codec:    CODEC_LEGACY,
+			command:  Command{Guid: "relay-1", Value: "1"},
+			withGuid: true,
+			want:     "relay-1#1",
+		},
+		{
+			name:     "with correlation id",
+			codec:    CODEC_LEGACY,
+			command:  Command{Guid: "relay-1", Value: "0", CorrelationID: "abc"},
+			withGuid: true,
+			want:     "relay-1#0#abc",
+		},
+		{
+			name:     "with channel",
+			codec:    CODEC_LEGACY,
+			command:  Command{Guid: "relay-1", Channel: 2, Value: "1", CorrelationID: "abc"},
+			withGuid: true,
+			want:     "relay-1#2:1#abc",
+		},
+		{
+			name:    "without guid",
+			codec:   CODEC_JSON,
+			command: Command{Guid: "relay-1", Value: "1"},
+			want:    "1",
+		},
+		{
+			name:     "ack",
+			codec:    CODEC_LEGACY,
+			command:  Command{Guid: "relay-1", Value: "1", CorrelationID: "abc", Status: "ACK"},
+			withGuid: true,
+			want:     "relay-1#1#abc#ACK",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := EncodeCommand(tt.codec, &tt.command, tt.withGuid)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(payload) != tt.want {
+				t.Errorf("payload = %q, want %q", payload, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeCommandCbor(t *testing.T) {
+	command := &Command{Guid: "relay-1", Channel: 3, Value: "1", CorrelationID: "abc"}
+
+	payload, err := EncodeCommand(CODEC_CBOR, command, true)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	guid, body := SplitGuid(payload)
+
+	if guid != "relay-1" {
+		t.Fatalf("guid = %q, want relay-1", guid)
+	}
+
+	var decoded map[string]any
+
+	if err := cbor.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("invalid cbor body: %v", err)
+	}
+
+	if _, ok := decoded["guid"]; ok {
+		t.Errorf("cbor body should not repeat the guid: %v", decoded)
+	}
+
+	if decoded["ch"] != uint64(3) || decoded["value"] != "1" || decoded["id"] != "abc" {
+		t.Errorf("decoded = %v", decoded)
+	}
+}
+
+func TestDecodeCommand(t *testing.T) {
+	cborAck, _ := EncodeCommand(CODEC_CBOR, &Command{Guid: "relay-1", Value: "1", CorrelationID: "abc", Status: "ACK"}, true)
+
+	tests := []struct {
+		name    string
+		payload []byte
+		want    *Command
+		wantErr bool
+	}{
+		{name: "plain", payload: []byte("relay-1#1"), want: &Command{Guid: "relay-1", Value: "1"}},
+		{name: "correlation id", payload: []byte("relay-1#0#abc"), want: &Command{Guid: "relay-1", Value: "0", CorrelationID: "abc"}},
+		{name: "ack with channel", payload: []byte("relay-1#2:1#abc#ACK"), want: &Command{Guid: "relay-1", Channel: 2, Value: "1", CorrelationID: "abc", Status: "ACK"}},
+		{name: "cbor ack", payload: cborAck, want: &Command{Guid: "relay-1", Value: "1", CorrelationID: "abc", Status: "ACK"}},
+		{name: "invalid channel", payload: []byte("relay-1#x:1#abc"), wantErr: true},
+		{name: "zero channel", payload: []byte("relay-1#0:1#abc"), wantErr: true},
+		{name: "missing value", payload: []byte("relay-1"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := DecodeCommand(tt.payload)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", command)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(command, tt.want) {
+				t.Errorf("command = %+v, want %+v", command, tt.want)
+			}
+		})
+	}
+}
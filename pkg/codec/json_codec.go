@@ -0,0 +1,174 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type jsonTelemetry struct {
+	Guid   string                     `json:"guid"`
+	Ts     json.RawMessage            `json:"ts"`
+	Values map[string]json.RawMessage `json:"values"`
+	Units  map[string]string          `json:"units"`
+}
+
+type JsonCodec struct{}
+
+func (JsonCodec) Name() string {
+	return "json"
+}
+
+func (JsonCodec) Detect(payload []byte) bool {
+	return startsLikeDocument(payload)
+}
+
+func (JsonCodec) Decode(payload []byte, guid string) (*Telemetry, error) {
+	var document jsonTelemetry
+
+	if err := json.Unmarshal(payload, &document); err != nil {
+		return nil, fmt.Errorf("invalid json telemetry: %w", err)
+	}
+
+	timestamp, err := parseJsonTimestamp(document.Ts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(document.Values))
+
+	for name, raw := range document.Values {
+		var value any
+
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("invalid json telemetry value %q: %w", name, err)
+		}
+
+		values[name] = value
+	}
+
+	return buildTelemetry(document.Guid, guid, timestamp, values, document.Units)
+}
+
+func parseJsonTimestamp(raw json.RawMessage) (*time.Time, error) {
+	raw = bytes.TrimSpace(raw)
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	if raw[0] == '"' {
+		var text string
+
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, fmt.Errorf("invalid telemetry timestamp: %w", err)
+		}
+
+		return parseTimestamp(text)
+	}
+
+	var number float64
+
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return nil, fmt.Errorf("invalid telemetry timestamp: %w", err)
+	}
+
+	return unixTimestamp(number), nil
+}
+
+func parseTimestamp(text string) (*time.Time, error) {
+	if number, err := strconv.ParseFloat(text, 64); err == nil {
+		return unixTimestamp(number), nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, text)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid telemetry timestamp %q: %w", text, err)
+	}
+
+	return &timestamp, nil
+}
+
+func unixTimestamp(number float64) *time.Time {
+	var timestamp time.Time
+
+	if number > 1e12 {
+		timestamp = time.UnixMilli(int64(number))
+	} else {
+		timestamp = time.Unix(0, int64(number*float64(time.Second)))
+	}
+
+	return &timestamp
+}
+
+func buildTelemetry(documentGuid, topicGuid string, timestamp *time.Time, values map[string]any, units map[string]string) (*Telemetry, error) {
+	guid := documentGuid
+
+	if guid == "" {
+		guid = topicGuid
+	}
+
+	if guid == "" {
+		return nil, fmt.Errorf("telemetry is missing the device guid")
+	}
+
+	if topicGuid != "" && documentGuid != "" && topicGuid != documentGuid {
+		return nil, fmt.Errorf("telemetry guid %s does not match topic guid %s", documentGuid, topicGuid)
+	}
+
+	names := make([]string, 0, len(values))
+
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	telemetry := &Telemetry{Guid: guid, Timestamp: timestamp}
+
+	for _, name := range names {
+		value, err := formatValue(values[name])
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid telemetry value %q: %w", name, err)
+		}
+
+		telemetry.Readings = append(telemetry.Readings, Reading{
+			Name:  name,
+			Value: value,
+			Unit:  units[name],
+		})
+	}
+
+	return telemetry, nil
+}
+
+func formatValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+
+		return "0", nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case nil:
+		return "", fmt.Errorf("value is null")
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
@@ -0,0 +1,29 @@
+package codec
+
+import (
+	"bytes"
+	"fmt"
+)
+
+type LegacyCodec struct{}
+
+func (LegacyCodec) Name() string {
+	return "legacy"
+}
+
+func (LegacyCodec) Detect(payload []byte) bool {
+	return bytes.IndexByte(payload, '#') > 0
+}
+
+func (LegacyCodec) Decode(payload []byte, guid string) (*Telemetry, error) {
+	parts := bytes.SplitN(payload, []byte("#"), 2)
+
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("invalid payload %q, expected guid#value", string(payload))
+	}
+
+	return &Telemetry{
+		Guid:     string(parts[0]),
+		Readings: []Reading{{Value: string(parts[1])}},
+	}, nil
+}
@@ -0,0 +1,145 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+var ErrUnknownFormat = errors.New("unknown telemetry format")
+
+const (
+	CODEC_AUTO   = ""
+	CODEC_LEGACY = "legacy"
+	CODEC_JSON   = "json"
+	CODEC_CBOR   = "cbor"
+)
+
+type Reading struct {
+	Name  string
+	Value string
+	Unit  string
+}
+
+type Telemetry struct {
+	Guid      string
+	Timestamp *time.Time
+	Readings  []Reading
+}
+
+type Codec interface {
+	Name() string
+	Detect(payload []byte) bool
+	Decode(payload []byte, guid string) (*Telemetry, error)
+}
+
+var codecs = []Codec{
+	JsonCodec{},
+	CborCodec{},
+	LegacyCodec{},
+}
+
+func Lookup(name string) (Codec, bool) {
+	for _, codec := range codecs {
+		if codec.Name() == name {
+			return codec, true
+		}
+	}
+
+	return nil, false
+}
+
+func Decode(payload []byte) (*Telemetry, error) {
+	return DecodeAs(CODEC_AUTO, payload)
+}
+
+func DecodeAs(name string, payload []byte) (*Telemetry, error) {
+	guid, body := SplitGuid(payload)
+
+	for _, codec := range codecs {
+		if name != CODEC_AUTO && codec.Name() != name {
+			continue
+		}
+
+		if name == CODEC_AUTO && !codec.Detect(body) {
+			continue
+		}
+
+		if _, isLegacy := codec.(LegacyCodec); isLegacy {
+			guid, body = "", payload
+		}
+
+		telemetry, err := codec.Decode(body, guid)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(telemetry.Readings) == 0 {
+			return nil, errors.New("telemetry has no readings")
+		}
+
+		return telemetry, nil
+	}
+
+	if name != CODEC_AUTO {
+		return nil, fmt.Errorf("unsupported codec %q", name)
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+func SplitGuid(payload []byte) (string, []byte) {
+	trimmed := bytes.TrimLeft(payload, " \t\r\n")
+
+	if isDocument(trimmed) {
+		return "", trimmed
+	}
+
+	if index := bytes.IndexByte(payload, '#'); index > 0 {
+		body := payload[index+1:]
+
+		if isDocument(bytes.TrimLeft(body, " \t\r\n")) {
+			return string(bytes.TrimSpace(payload[:index])), bytes.TrimLeft(body, " \t\r\n")
+		}
+	}
+
+	return "", bytes.TrimSpace(payload)
+}
+
+func PeekGuid(payload []byte) string {
+	guid, body := SplitGuid(payload)
+
+	if guid != "" {
+		return guid
+	}
+
+	var document struct {
+		Guid string `json:"guid" cbor:"guid"`
+	}
+
+	switch {
+	case startsLikeDocument(body):
+		json.Unmarshal(body, &document)
+	case startsLikeCborMap(body):
+		cbor.Unmarshal(body, &document)
+	default:
+		if index := bytes.IndexByte(body, '#'); index > 0 {
+			return string(body[:index])
+		}
+	}
+
+	return document.Guid
+}
+
+func isDocument(payload []byte) bool {
+	return startsLikeDocument(payload) || startsLikeCborMap(payload)
+}
+
+func startsLikeDocument(payload []byte) bool {
+	return len(payload) > 0 && payload[0] == '{'
+}
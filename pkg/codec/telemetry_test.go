@@ -0,0 +1,122 @@
+package codec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+func mustCbor(t *testing.T, value any) []byte {
+	t.Helper()
+
+	body, err := cbor.Marshal(value)
+
+	if err != nil {
+		t.Fatalf("failed to encode cbor: %v", err)
+	}
+
+	return body
+}
+
+func TestDecodeAs(t *testing.T) {
+	cborBody := mustCbor(t, map[string]any{
+		"values": map[string]any{"temperature": 21.5, "humidity": 60},
+		"units":  map[string]string{"temperature": "C"},
+	})
+
+	tests := []struct {
+		name     string
+		codec    string
+		payload  []byte
+		guid     string
+		readings []Reading
+		wantErr  bool
+		target   error
+	}{
+		{
+			name:     "legacy",
+			codec:    CODEC_AUTO,
+			payload:  []byte("sensor-1#0101"),
+			guid:     "sensor-1",
+			readings: []Reading{{Value: "0101"}},
+		},
+		{
+			name:     "json with guid in body",
+			codec:    CODEC_AUTO,
+			payload:  []byte(`{"guid":"sensor-1","values":{"temperature":23.4,"on":true},"units":{"temperature":"°C"}}`),
+			guid:     "sensor-1",
+			readings: []Reading{{Name: "on", Value: "1"}, {Name: "temperature", Value: "23.4", Unit: "°C"}},
+		},
+		{
+			name:     "json with guid prefix",
+			codec:    CODEC_JSON,
+			payload:  []byte(`sensor-2#{"values":{"gas":120}}`),
+			guid:     "sensor-2",
+			readings: []Reading{{Name: "gas", Value: "120"}},
+		},
+		{
+			name:     "cbor with guid prefix",
+			codec:    CODEC_AUTO,
+			payload:  append([]byte("sensor-3#"), cborBody...),
+			guid:     "sensor-3",
+			readings: []Reading{{Name: "humidity", Value: "60"}, {Name: "temperature", Value: "21.5", Unit: "C"}},
+		},
+		{
+			name:    "json guid mismatch",
+			codec:   CODEC_AUTO,
+			payload: []byte(`sensor-2#{"guid":"sensor-1","values":{"gas":1}}`),
+			wantErr: true,
+		},
+		{
+			name:    "json without readings",
+			codec:   CODEC_JSON,
+			payload: []byte(`{"guid":"sensor-1","values":{}}`),
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			codec:   CODEC_AUTO,
+			payload: []byte("no separator"),
+			wantErr: true,
+			target:  ErrUnknownFormat,
+		},
+		{
+			name:    "unsupported codec",
+			codec:   "xml",
+			payload: []byte("sensor-1#1"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			telemetry, err := DecodeAs(tt.codec, tt.payload)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", telemetry)
+				}
+
+				if tt.target != nil && !errors.Is(err, tt.target) {
+					t.Fatalf("error = %v, want %v", err, tt.target)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if telemetry.Guid != tt.guid {
+				t.Errorf("guid = %q, want %q", telemetry.Guid, tt.guid)
+			}
+
+			if !reflect.DeepEqual(telemetry.Readings, tt.readings) {
+				t.Errorf("readings = %+v, want %+v", telemetry.Readings, tt.readings)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package dto
 
-import "go/hioto/pkg/enum"
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
 
 type ControlLocalDto struct {
 	Type    enum.EDeviceType `json:"type" validate:"required"`
@@ -14,3 +17,22 @@ type ControlGasDetector struct {
 	Condition   int    `json:"condition"`
 	Description string `json:"description" validate:"required"`
 }
+
+type ResponseChannelDto struct {
+	Channel   int       `json:"channel"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ResponseCommandDto struct {
+	CorrelationID string              `json:"correlation_id"`
+	Guid          string              `json:"guid"`
+	Channel       int                 `json:"channel,omitempty"`
+	Value         string              `json:"value"`
+	Source        enum.ECommandSource `json:"source"`
+	Status        enum.ECommandStatus `json:"status"`
+	Error         string              `json:"error,omitempty"`
+	ExpiresAt     time.Time           `json:"expires_at"`
+	ConfirmedAt   *time.Time          `json:"confirmed_at"`
+	CreatedAt     time.Time           `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import (
+	"encoding/json"
+	"go/hioto/pkg/enum"
+	"time"
+)
+
+type EnvelopeDto struct {
+	ID       string            `json:"id"`
+	Type     enum.EMessageType `json:"type"`
+	Version  int               `json:"version"`
+	IssuedAt *time.Time        `json:"issued_at"`
+	Payload  json.RawMessage   `json:"payload"`
+}
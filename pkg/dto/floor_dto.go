@@ -1,6 +1,9 @@
 package dto
 
-import "time"
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
 
 type CreateFloorDto struct {
 	Name string `json:"name" validate:"required"`
@@ -12,6 +15,7 @@ type UpdateFloorDto struct {
 
 type ResponseAllFloorDto struct {
 	ID        uint      `json:"id"`
+	Guid      string    `json:"guid"`
 	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -24,8 +28,25 @@ type ResponseFloorSimpleDto struct {
 
 type ResponseFloorDto struct {
 	ID        uint              `json:"id"`
+	Guid      string            `json:"guid"`
 	Name      string            `json:"name"`
 	Rooms     []ResponseRoomDto `json:"rooms"`
 	CreatedAt time.Time         `json:"created_at"`
 	UpdatedAt time.Time         `json:"updated_at"`
 }
+
+type ResCloudFloorDto struct {
+	Action    enum.ELayoutAction `json:"action"`
+	MacServer string             `json:"mac_server"`
+	ID        uint               `json:"id"`
+	Guid      string             `json:"guid"`
+	Name      string             `json:"name"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+type ReqCloudFloorDto struct {
+	Action enum.ELayoutAction `json:"action" validate:"required,oneof=create update delete"`
+	Guid   string             `json:"guid" validate:"required"`
+	Name   string             `json:"name" validate:"required_unless=Action delete"`
+}
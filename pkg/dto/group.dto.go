@@ -0,0 +1,67 @@
+package dto
+
+import "time"
+
+type CreateGroupDto struct {
+	Tag         string   `json:"tag" validate:"required,max=64,excludesall=0x2C0x20"`
+	Name        string   `json:"name" validate:"required"`
+	DeviceGuids []string `json:"device_guids"`
+}
+
+type UpdateGroupDto struct {
+	Tag         string    `json:"tag" validate:"required,max=64,excludesall=0x2C0x20"`
+	Name        string    `json:"name" validate:"required"`
+	DeviceGuids *[]string `json:"device_guids"`
+}
+
+type ReqBulkControlDto struct {
+	Value string `json:"value" validate:"required,oneof=0 1"`
+}
+
+type ReqCloudBulkControlDto struct {
+	Guid  string `json:"guid" validate:"required"`
+	Value string `json:"value" validate:"required,oneof=0 1"`
+}
+
+type ResponseGroupDto struct {
+	ID        uint                     `json:"id"`
+	Tag       string                   `json:"tag"`
+	Name      string                   `json:"name"`
+	Devices   []ResponseGroupDeviceDto `json:"devices"`
+	CreatedAt time.Time                `json:"created_at"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+type ResponseGroupDeviceDto struct {
+	Guid   string `json:"guid"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+type ResponseDeviceControlDto struct {
+	Guid    string              `json:"guid"`
+	Name    string              `json:"name"`
+	Success bool                `json:"success"`
+	Skipped bool                `json:"skipped,omitempty"`
+	Error   string              `json:"error,omitempty"`
+	Command *ResponseCommandDto `json:"command,omitempty"`
+}
+
+type ResCloudBulkControlDto struct {
+	MacServer string `json:"mac_server"`
+	Scope     string `json:"scope"`
+	Guid      string `json:"guid"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+	ResponseBulkControlDto
+}
+
+type ResponseBulkControlDto struct {
+	Value     string                     `json:"value"`
+	Total     int                        `json:"total"`
+	Succeeded int                        `json:"succeeded"`
+	Failed    int                        `json:"failed"`
+	Skipped   int                        `json:"skipped"`
+	Results   []ResponseDeviceControlDto `json:"results"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+type HeartbeatDevicesDto struct {
+	Total   int64 `json:"total"`
+	Online  int64 `json:"online"`
+	Offline int64 `json:"offline"`
+}
+
+type HeartbeatErrorsDto struct {
+	Window         string `json:"window"`
+	Quarantined    int64  `json:"quarantined"`
+	CommandsFailed int64  `json:"commands_failed"`
+	OutboxFailed   int64  `json:"outbox_failed"`
+}
+
+type HeartbeatDto struct {
+	MacServer     string              `json:"mac_server"`
+	Status        string              `json:"status"`
+	Version       string              `json:"version"`
+	StartedAt     time.Time           `json:"started_at"`
+	Timestamp     time.Time           `json:"timestamp"`
+	UptimeSeconds int64               `json:"uptime_seconds"`
+	DbSizeBytes   int64               `json:"db_size_bytes"`
+	Devices       HeartbeatDevicesDto `json:"devices"`
+	Mqtt          map[string]string   `json:"mqtt"`
+	Rmq           map[string]string   `json:"rmq"`
+	OutboxBacklog int64               `json:"outbox_backlog"`
+	Errors        HeartbeatErrorsDto  `json:"errors"`
+}
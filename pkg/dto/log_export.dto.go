@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
+
+type ExportLogDto struct {
+	ID          uint      `json:"id"`
+	InputGuid   string    `json:"input_guid"`
+	InputName   string    `json:"input_name"`
+	InputValue  string    `json:"input_value"`
+	OutputGuid  string    `json:"output_guid"`
+	OutputValue string    `json:"output_value"`
+	Time        time.Time `json:"time"`
+}
+
+type ExportLogAktuatorDto struct {
+	ID      uint      `json:"id"`
+	Guid    string    `json:"guid"`
+	Channel int       `json:"channel"`
+	Name    string    `json:"name"`
+	Value   string    `json:"value"`
+	Time    time.Time `json:"time"`
+}
+
+type ExportMonitoringDto struct {
+	ID         uint             `json:"id"`
+	DeviceGuid string           `json:"device_guid"`
+	DeviceName string           `json:"device_name"`
+	DeviceType enum.EDeviceType `json:"device_type"`
+	Name       string           `json:"name"`
+	Value      string           `json:"value"`
+	Unit       string           `json:"unit"`
+	Time       time.Time        `json:"time"`
+}
+
+type LogExportBatchDto struct {
+	MacServer  string    `json:"mac_server"`
+	Stream     string    `json:"stream"`
+	FromID     uint      `json:"from_id"`
+	ToID       uint      `json:"to_id"`
+	Count      int       `json:"count"`
+	ExportedAt time.Time `json:"exported_at"`
+	Items      any       `json:"items"`
+}
+
+type ResponseExportCursorDto struct {
+	Stream       string     `json:"stream"`
+	LastID       uint       `json:"last_id"`
+	Pending      int64      `json:"pending"`
+	LastExportAt *time.Time `json:"last_export_at"`
+	LastError    string     `json:"last_error"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
+
+type ResponseOutboxDto struct {
+	ID            uint               `json:"id"`
+	InstanceName  string             `json:"instance_name"`
+	Exchange      string             `json:"exchange"`
+	QueueName     string             `json:"queue_name"`
+	Payload       string             `json:"payload"`
+	Status        enum.EOutboxStatus `json:"status"`
+	Attempts      int                `json:"attempts"`
+	LastError     string             `json:"last_error"`
+	NextAttemptAt time.Time          `json:"next_attempt_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
+
+type PresenceEventDto struct {
+	MacServer string           `json:"mac_server"`
+	Guid      string           `json:"guid"`
+	Name      string           `json:"name"`
+	Type      enum.EDeviceType `json:"type"`
+	Status    string           `json:"status"`
+	LastSeen  time.Time        `json:"last_seen"`
+	Time      time.Time        `json:"time"`
+}
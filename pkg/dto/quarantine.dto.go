@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
+
+type ResponseQuarantineDto struct {
+	ID            uint                   `json:"id"`
+	Broker        string                 `json:"broker"`
+	Route         string                 `json:"route"`
+	Destination   string                 `json:"destination"`
+	Payload       string                 `json:"payload"`
+	PayloadBase64 string                 `json:"payload_base64"`
+	Error         string                 `json:"error"`
+	Status        enum.EQuarantineStatus `json:"status"`
+	Attempts      int                    `json:"attempts"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+type UpdateQuarantineDto struct {
+	Payload       *string `json:"payload" validate:"required_without=PayloadBase64"`
+	PayloadBase64 *string `json:"payload_base64" validate:"required_without=Payload,omitempty,base64"`
+}
+
+type GetQuarantinePagination struct {
+	PaginationRequest
+	Status      string `json:"status" query:"status" validate:"omitempty"`
+	Destination string `json:"destination" query:"destination" validate:"omitempty"`
+}
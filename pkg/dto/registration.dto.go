@@ -6,51 +6,61 @@ import (
 )
 
 type RegistrationDto struct {
-	Guid     string           `json:"guid" validate:"required"`
-	Mac      string           `json:"mac" validate:"required"`
-	Type     enum.EDeviceType `json:"type" validate:"required"`
-	Quantity int              `json:"quantity" validate:"required,min=1"`
-	Name     string           `json:"name" validate:"required"`
-	Version  string           `json:"version" validate:"required"`
-	Minor    string           `json:"minor" validate:"required"`
-	RoomID   *uint            `json:"room_id"`
+	Guid         string             `json:"guid" validate:"required"`
+	Mac          string             `json:"mac" validate:"required"`
+	Type         enum.EDeviceType   `json:"type" validate:"required"`
+	Quantity     int                `json:"quantity" validate:"required,min=1"`
+	Name         string             `json:"name" validate:"required"`
+	Version      string             `json:"version" validate:"required"`
+	Minor        string             `json:"minor" validate:"required"`
+	RoomID       *uint              `json:"room_id"`
+	TopicScheme  enum.ETopicScheme  `json:"topic_scheme" validate:"omitempty,oneof=legacy hierarchical"`
+	PayloadCodec enum.EPayloadCodec `json:"payload_codec" validate:"omitempty,oneof=text cbor"`
 }
 
 type ResponseDeviceListDto struct {
-	ID           uint             `json:"id"`
-	Guid         string           `json:"guid"`
-	Mac          string           `json:"mac"`
-	Type         enum.EDeviceType `json:"type"`
-	Quantity     int              `json:"quantity"`
-	Name         string           `json:"name"`
-	Version      string           `json:"version"`
-	Minor        string           `json:"minor"`
-	Status       string           `json:"status"`
-	StatusDevice string           `json:"status_device"`
-	LastSeen     time.Time        `json:"last_seen"`
-	CreatedAt    time.Time        `json:"created_at"`
-	UpdatedAt    time.Time        `json:"updated_at"`
-	RoomName     *string          `json:"room"`
+	ID           uint               `json:"id"`
+	Guid         string             `json:"guid"`
+	Mac          string             `json:"mac"`
+	Type         enum.EDeviceType   `json:"type"`
+	Quantity     int                `json:"quantity"`
+	Name         string             `json:"name"`
+	Version      string             `json:"version"`
+	Minor        string             `json:"minor"`
+	Status       string             `json:"status"`
+	StatusDevice string             `json:"status_device"`
+	TopicScheme  enum.ETopicScheme  `json:"topic_scheme"`
+	PayloadCodec enum.EPayloadCodec `json:"payload_codec"`
+	LastSeen     time.Time          `json:"last_seen"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	RoomName     *string            `json:"room"`
+	Tags         []string           `json:"tags"`
 }
 
 type ResponseDeviceDetailDto struct {
-	ID           uint             `json:"id"`
-	Guid         string           `json:"guid"`
-	Mac          string           `json:"mac"`
-	Type         enum.EDeviceType `json:"type"`
-	Quantity     int              `json:"quantity"`
-	Name         string           `json:"name"`
-	Version      string           `json:"version"`
-	Minor        string           `json:"minor"`
-	Status       string           `json:"status"`
-	StatusDevice string           `json:"status_device"`
-	LastSeen     time.Time        `json:"last_seen"`
-	CreatedAt    time.Time        `json:"created_at"`
-	UpdatedAt    time.Time        `json:"updated_at"`
-	RoomID       *uint            `json:"id_room"`
-	RoomName     *string          `json:"room"`
-	FloorID      *uint            `json:"id_floor"`
-	FloorName    *string          `json:"floor"`
+	ID           uint                 `json:"id"`
+	Guid         string               `json:"guid"`
+	Mac          string               `json:"mac"`
+	Type         enum.EDeviceType     `json:"type"`
+	Quantity     int                  `json:"quantity"`
+	Name         string               `json:"name"`
+	Version      string               `json:"version"`
+	Minor        string               `json:"minor"`
+	Status       string               `json:"status"`
+	StatusDevice string               `json:"status_device"`
+	TopicScheme  enum.ETopicScheme    `json:"topic_scheme"`
+	PayloadCodec enum.EPayloadCodec   `json:"payload_codec"`
+	LastSeen     time.Time            `json:"last_seen"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	RoomID       *uint                `json:"id_room"`
+	RoomGuid     *string              `json:"room_guid"`
+	RoomName     *string              `json:"room"`
+	FloorID      *uint                `json:"id_floor"`
+	FloorGuid    *string              `json:"floor_guid"`
+	FloorName    *string              `json:"floor"`
+	Channels     []ResponseChannelDto `json:"channels,omitempty"`
 }
 
 type ResCloudDeviceDto struct {
@@ -59,14 +69,16 @@ type ResCloudDeviceDto struct {
 }
 
 type ReqUpdateDeviceDto struct {
-	Guid     string           `json:"guid" validate:"required"`
-	Mac      string           `json:"mac" validate:"required"`
-	Type     enum.EDeviceType `json:"type" validate:"required"`
-	Quantity int              `json:"quantity" validate:"required,min=1"`
-	Name     string           `json:"name" validate:"required"`
-	Version  string           `json:"version" validate:"required"`
-	Minor    string           `json:"minor" validate:"required"`
-	RoomID   *uint            `json:"room_id"`
+	Guid         string              `json:"guid" validate:"required"`
+	Mac          string              `json:"mac" validate:"required"`
+	Type         enum.EDeviceType    `json:"type" validate:"required"`
+	Quantity     int                 `json:"quantity" validate:"required,min=1"`
+	Name         string              `json:"name" validate:"required"`
+	Version      string              `json:"version" validate:"required"`
+	Minor        string              `json:"minor" validate:"required"`
+	RoomID       *uint               `json:"room_id"`
+	TopicScheme  *enum.ETopicScheme  `json:"topic_scheme" validate:"omitempty,oneof=legacy hierarchical"`
+	PayloadCodec *enum.EPayloadCodec `json:"payload_codec" validate:"omitempty,oneof=text cbor"`
 }
 
 type ReqDeleteDeviceFromCloudDto struct {
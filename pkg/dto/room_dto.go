@@ -1,6 +1,9 @@
 package dto
 
-import "time"
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
 
 type CreateRoomDto struct {
 	Name    string `json:"name" validate:"required"`
@@ -20,8 +23,28 @@ type ResponseRoomSimpleDto struct {
 
 type ResponseRoomDto struct {
 	ID        uint      `json:"id"`
+	Guid      string    `json:"guid"`
 	Name      string    `json:"name"`
 	FloorID   uint      `json:"floor_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+type ResCloudRoomDto struct {
+	Action    enum.ELayoutAction `json:"action"`
+	MacServer string             `json:"mac_server"`
+	ID        uint               `json:"id"`
+	Guid      string             `json:"guid"`
+	Name      string             `json:"name"`
+	FloorID   uint               `json:"floor_id"`
+	FloorGuid string             `json:"floor_guid"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+type ReqCloudRoomDto struct {
+	Action    enum.ELayoutAction `json:"action" validate:"required,oneof=create update delete"`
+	Guid      string             `json:"guid" validate:"required"`
+	Name      string             `json:"name" validate:"required_unless=Action delete"`
+	FloorGuid string             `json:"floor_guid" validate:"required_unless=Action delete"`
+}
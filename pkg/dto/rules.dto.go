@@ -11,14 +11,33 @@ type CreateRuleDto struct {
 	OutputGuid []string `json:"output_guid" validate:"required,min=1,max=8"`
 }
 
+type ReqCloudRuleDto struct {
+	CreateRuleDto
+	Replace bool `json:"replace"`
+}
+
+type ReqDeleteRuleDto struct {
+	InputGuid string `json:"input_guid" validate:"required"`
+}
+
+type ResponseRuleResultDto struct {
+	MacServer string            `json:"mac_server"`
+	Action    string            `json:"action"`
+	InputGuid string            `json:"input_guid"`
+	Success   bool              `json:"success"`
+	Error     string            `json:"error,omitempty"`
+	Rules     []ResponseRuleDto `json:"rules"`
+}
+
 type ResponseRuleDto struct {
-	MacServer   string `json:"mac_server"`
-	InputGuid   string `json:"input_guid"`
-	InputValue  string `json:"input_value"`
-	OutputGuid  string `json:"output_guid"`
-	OutputValue string `json:"output_value"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	MacServer     string `json:"mac_server"`
+	InputGuid     string `json:"input_guid"`
+	InputValue    string `json:"input_value"`
+	OutputGuid    string `json:"output_guid"`
+	OutputChannel int    `json:"output_channel,omitempty"`
+	OutputValue   string `json:"output_value"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
 }
 
 type ResponseGetRulesDto struct {
@@ -28,6 +47,7 @@ type ResponseGetRulesDto struct {
 	SensorInputValue    string `json:"sensor_input_value"`
 	GuidAktuator        string `json:"guid_aktuator"`
 	AktuatorName        string `json:"aktuator_name"`
+	AktuatorChannel     int    `json:"aktuator_channel"`
 	AktuatorOutputValue string `json:"aktuator_output_value"`
 	CreatedAt           string `json:"created_at"`
 	UpdatedAt           string `json:"updated_at"`
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
+
+type SnapshotDeviceDto struct {
+	Guid     string           `json:"guid"`
+	Mac      string           `json:"mac"`
+	Type     enum.EDeviceType `json:"type"`
+	Quantity int              `json:"quantity"`
+	Name     string           `json:"name"`
+	Version  string           `json:"version"`
+	Minor    string           `json:"minor"`
+	RoomGuid *string          `json:"room_guid"`
+}
+
+type SnapshotRuleDto struct {
+	InputGuid     string `json:"input_guid"`
+	InputValue    string `json:"input_value"`
+	OutputGuid    string `json:"output_guid"`
+	OutputChannel int    `json:"output_channel"`
+	OutputValue   string `json:"output_value"`
+}
+
+type SnapshotFloorDto struct {
+	Guid string `json:"guid"`
+	Name string `json:"name"`
+}
+
+type SnapshotRoomDto struct {
+	Guid      string `json:"guid"`
+	Name      string `json:"name"`
+	FloorGuid string `json:"floor_guid"`
+}
+
+type SnapshotDto struct {
+	MacServer   string              `json:"mac_server"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	Digest      string              `json:"digest"`
+	Items       map[string]string   `json:"items"`
+	Devices     []SnapshotDeviceDto `json:"devices"`
+	Rules       []SnapshotRuleDto   `json:"rules"`
+	Floors      []SnapshotFloorDto  `json:"floors"`
+	Rooms       []SnapshotRoomDto   `json:"rooms"`
+}
+
+type ReqSyncDto struct {
+	MacServer string              `json:"mac_server"`
+	Digest    string              `json:"digest"`
+	Items     map[string]string   `json:"items"`
+	Devices   []SnapshotDeviceDto `json:"devices"`
+	Rules     []SnapshotRuleDto   `json:"rules"`
+	Floors    []SnapshotFloorDto  `json:"floors"`
+	Rooms     []SnapshotRoomDto   `json:"rooms"`
+}
+
+type SyncReportDto struct {
+	MacServer    string    `json:"mac_server"`
+	Digest       string    `json:"digest"`
+	CloudDigest  string    `json:"cloud_digest"`
+	InSync       bool      `json:"in_sync"`
+	LocalOnly    []string  `json:"local_only"`
+	CloudOnly    []string  `json:"cloud_only"`
+	Changed      []string  `json:"changed"`
+	Applied      []string  `json:"applied"`
+	Conflicts    []string  `json:"conflicts"`
+	ReconciledAt time.Time `json:"reconciled_at"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+type ResponseTelemetryStatsDto struct {
+	Metric string    `json:"metric"`
+	Unit   string    `json:"unit"`
+	Count  int64     `json:"count"`
+	Avg    float64   `json:"avg"`
+	Min    float64   `json:"min"`
+	Max    float64   `json:"max"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+}
+
+type ResponseTelemetryBucketDto struct {
+	Time  time.Time `json:"time"`
+	Count int64     `json:"count"`
+	Avg   float64   `json:"avg"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+}
+
+type ResponseTelemetrySeriesDto struct {
+	Guid    string                       `json:"guid"`
+	Metric  string                       `json:"metric"`
+	Unit    string                       `json:"unit"`
+	Bucket  string                       `json:"bucket"`
+	From    time.Time                    `json:"from"`
+	To      time.Time                    `json:"to"`
+	Buckets []ResponseTelemetryBucketDto `json:"buckets"`
+}
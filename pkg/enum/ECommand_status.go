@@ -0,0 +1,19 @@
+package enum
+
+type ECommandStatus string
+
+const (
+	COMMAND_PENDING   ECommandStatus = "PENDING"
+	COMMAND_SENT      ECommandStatus = "SENT"
+	COMMAND_CONFIRMED ECommandStatus = "CONFIRMED"
+	COMMAND_REJECTED  ECommandStatus = "REJECTED"
+	COMMAND_FAILED    ECommandStatus = "FAILED"
+)
+
+type ECommandSource string
+
+const (
+	COMMAND_SOURCE_LOCAL ECommandSource = "local"
+	COMMAND_SOURCE_CLOUD ECommandSource = "cloud"
+	COMMAND_SOURCE_RULE  ECommandSource = "rule"
+)
@@ -0,0 +1,12 @@
+package enum
+
+type EDeviceEvent string
+
+const (
+	DEVICE_REGISTERED    EDeviceEvent = "REGISTERED"
+	DEVICE_UPDATED       EDeviceEvent = "UPDATED"
+	DEVICE_DELETED       EDeviceEvent = "DELETED"
+	DEVICE_STATE_CHANGED EDeviceEvent = "STATE_CHANGED"
+	DEVICE_ONLINE        EDeviceEvent = "ONLINE"
+	DEVICE_OFFLINE       EDeviceEvent = "OFFLINE"
+)
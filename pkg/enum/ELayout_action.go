@@ -0,0 +1,9 @@
+package enum
+
+type ELayoutAction string
+
+const (
+	LAYOUT_CREATE ELayoutAction = "create"
+	LAYOUT_UPDATE ELayoutAction = "update"
+	LAYOUT_DELETE ELayoutAction = "delete"
+)
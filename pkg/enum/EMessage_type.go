@@ -0,0 +1,17 @@
+package enum
+
+type EMessageType string
+
+const (
+	MESSAGE_CONTROL       EMessageType = "control"
+	MESSAGE_REGISTER      EMessageType = "register"
+	MESSAGE_UPDATE_DEVICE EMessageType = "update_device"
+	MESSAGE_DELETE_DEVICE EMessageType = "delete_device"
+	MESSAGE_RULES         EMessageType = "rules"
+	MESSAGE_RULES_DELETE  EMessageType = "rules_delete"
+	MESSAGE_SYNC          EMessageType = "sync"
+	MESSAGE_FLOOR         EMessageType = "floor"
+	MESSAGE_ROOM          EMessageType = "room"
+	MESSAGE_FLOOR_CONTROL EMessageType = "floor_control"
+	MESSAGE_ROOM_CONTROL  EMessageType = "room_control"
+)
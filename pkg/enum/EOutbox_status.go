@@ -0,0 +1,8 @@
+package enum
+
+type EOutboxStatus string
+
+const (
+	OUTBOX_PENDING EOutboxStatus = "PENDING"
+	OUTBOX_FAILED  EOutboxStatus = "FAILED"
+)
@@ -0,0 +1,8 @@
+package enum
+
+type EPayloadCodec string
+
+const (
+	PAYLOAD_CODEC_TEXT EPayloadCodec = "text"
+	PAYLOAD_CODEC_CBOR EPayloadCodec = "cbor"
+)
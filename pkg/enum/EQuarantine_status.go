@@ -0,0 +1,8 @@
+package enum
+
+type EQuarantineStatus string
+
+const (
+	QUARANTINED EQuarantineStatus = "QUARANTINED"
+	REINJECTED  EQuarantineStatus = "REINJECTED"
+)
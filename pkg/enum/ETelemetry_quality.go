@@ -0,0 +1,8 @@
+package enum
+
+type ETelemetryQuality string
+
+const (
+	TELEMETRY_GOOD      ETelemetryQuality = "good"
+	TELEMETRY_UNCERTAIN ETelemetryQuality = "uncertain"
+)
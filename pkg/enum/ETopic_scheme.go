@@ -0,0 +1,8 @@
+package enum
+
+type ETopicScheme string
+
+const (
+	TOPIC_SCHEME_LEGACY       ETopicScheme = "legacy"
+	TOPIC_SCHEME_HIERARCHICAL ETopicScheme = "hierarchical"
+)
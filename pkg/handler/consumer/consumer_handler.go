@@ -2,11 +2,18 @@ package consumer
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"go/hioto/config"
+	"go/hioto/pkg/codec"
 	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	messagebroker "go/hioto/pkg/handler/message_broker"
 	"go/hioto/pkg/service"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
 )
 
@@ -16,46 +23,78 @@ type ConsumerHandler struct {
 	ruleService          *service.RuleService
 	deviceService        *service.DeviceService
 	controlDeviceService *service.ControlDeviceService
-	validator            *validator.Validate
+	commandService       *service.CommandService
+	idempotencyService   *service.IdempotencyService
+	syncService          *service.SyncService
+	floorService         *service.FloorService
+	roomService          *service.RoomService
+	presenceService      *service.PresenceService
 }
 
-func NewConsumerHandler(ruleService *service.RuleService, deviceService *service.DeviceService, controlDeviceService *service.ControlDeviceService) *ConsumerHandler {
+func NewConsumerHandler(
+	ruleService *service.RuleService,
+	deviceService *service.DeviceService,
+	controlDeviceService *service.ControlDeviceService,
+	commandService *service.CommandService,
+	idempotencyService *service.IdempotencyService,
+	syncService *service.SyncService,
+	floorService *service.FloorService,
+	roomService *service.RoomService,
+	presenceService *service.PresenceService,
+) *ConsumerHandler {
 	return &ConsumerHandler{
 		ruleService:          ruleService,
 		deviceService:        deviceService,
 		controlDeviceService: controlDeviceService,
-		validator:            validator.New(),
+		commandService:       commandService,
+		idempotencyService:   idempotencyService,
+		syncService:          syncService,
+		floorService:         floorService,
+		roomService:          roomService,
+		presenceService:      presenceService,
 	}
 }
 
-func (h *ConsumerHandler) RegistrationHandler(message []byte) {
+func (h *ConsumerHandler) RegistrationHandler(message []byte) error {
 	var registrationDto dto.RegistrationDto
 
 	if err := json.Unmarshal(message, &registrationDto); err != nil {
 		log.Errorf("Failed to unmarshal registration message: %v", err)
-		return
+		return fmt.Errorf("invalid registration message: %w", err)
 	}
 
-	h.deviceService.RegisterDeviceLocal(&registrationDto)
+	if err := validate.Struct(registrationDto); err != nil {
+		log.Errorf("Validation error: %v", err)
+		return fmt.Errorf("invalid registration message: %w", err)
+	}
+
+	_, err := h.deviceService.RegisterDeviceLocal(&registrationDto)
+
+	return err
 }
 
-func (h *ConsumerHandler) RegistrationFromCloudHandler(message []byte) {
+func (h *ConsumerHandler) RegistrationFromCloudHandler(message []byte) error {
 	var registrationDto dto.RegistrationDto
 
 	if err := json.Unmarshal(message, &registrationDto); err != nil {
 		log.Errorf("Failed to unmarshal registration message: %v", err)
-		return
+		return fmt.Errorf("invalid registration message: %w", err)
+	}
+
+	if err := validate.Struct(registrationDto); err != nil {
+		log.Errorf("Validation error: %v", err)
+		return fmt.Errorf("invalid registration message: %w", err)
 	}
 
-	h.deviceService.RegisterDeviceCloud(&registrationDto)
+	return h.deviceService.RegisterDeviceCloud(&registrationDto)
 }
 
-func (h *ConsumerHandler) UpdateDeviceFromCloudHandler(message []byte) {
+func (h *ConsumerHandler) UpdateDeviceFromCloudHandler(message []byte) error {
 	var updateDeviceFromCloudDto dto.ReqUpdateDeviceDto
 
 	if err := json.Unmarshal(message, &updateDeviceFromCloudDto); err != nil {
 		log.Errorf("Failed to unmarshal update device message: %v", err)
-		return
+		return fmt.Errorf("invalid update device message: %w", err)
 	}
 
 	updateRawDeviceDto := dto.ReqUpdateDeviceDto{
@@ -68,82 +107,303 @@ func (h *ConsumerHandler) UpdateDeviceFromCloudHandler(message []byte) {
 		Minor:    updateDeviceFromCloudDto.Minor,
 	}
 
-	h.deviceService.UpdateDeviceRMQCloud(&updateRawDeviceDto)
+	return h.deviceService.UpdateDeviceRMQCloud(&updateRawDeviceDto)
 }
 
-func (h *ConsumerHandler) RulesHandler(message []byte) {
+func (h *ConsumerHandler) RulesHandler(message []byte) error {
 	var createRuleDto dto.CreateRuleDto
 
 	if err := json.Unmarshal(message, &createRuleDto); err != nil {
 		log.Errorf("Failed to unmarshal rule message: %v", err)
-		return
+		return fmt.Errorf("invalid rule message: %w", err)
+	}
+
+	if err := validate.Struct(createRuleDto); err != nil {
+		log.Errorf("Validation error: %v", err)
+		return fmt.Errorf("invalid rule message: %w", err)
+	}
+
+	_, err := h.ruleService.CreateRules(&createRuleDto)
+
+	return err
+}
+
+func (h *ConsumerHandler) RulesFromCloudHandler(message []byte) error {
+	var reqCloudRuleDto dto.ReqCloudRuleDto
+
+	if err := json.Unmarshal(message, &reqCloudRuleDto); err != nil {
+		log.Errorf("Failed to unmarshal rule message: %v", err)
+		return h.reportRuleResult(service.RULE_ACTION_CREATE, "", nil, fmt.Errorf("invalid rule message: %w", err))
+	}
+
+	action := service.RULE_ACTION_CREATE
+
+	if reqCloudRuleDto.Replace {
+		action = service.RULE_ACTION_REPLACE
+	}
+
+	if err := validate.Struct(reqCloudRuleDto); err != nil {
+		log.Errorf("Validation error: %v", err)
+		return h.reportRuleResult(action, reqCloudRuleDto.InputGuid, nil, fmt.Errorf("invalid rule message: %w", err))
 	}
 
-	h.ruleService.CreateRules(&createRuleDto)
+	rules, err := h.ruleService.SaveCloudRules(&reqCloudRuleDto.CreateRuleDto, reqCloudRuleDto.Replace)
+
+	return h.reportRuleResult(action, reqCloudRuleDto.InputGuid, rules, err)
 }
 
-func (h *ConsumerHandler) ControlHandler(message []byte) {
+func (h *ConsumerHandler) DeleteRulesFromCloudHandler(message []byte) error {
+	var reqDeleteRuleDto dto.ReqDeleteRuleDto
+
+	if err := json.Unmarshal(message, &reqDeleteRuleDto); err != nil {
+		log.Errorf("Failed to unmarshal delete rule message: %v", err)
+		return h.reportRuleResult(service.RULE_ACTION_DELETE, "", nil, fmt.Errorf("invalid delete rule message: %w", err))
+	}
+
+	if err := validate.Struct(reqDeleteRuleDto); err != nil {
+		log.Errorf("Validation error: %v", err)
+		return h.reportRuleResult(service.RULE_ACTION_DELETE, reqDeleteRuleDto.InputGuid, nil, fmt.Errorf("invalid delete rule message: %w", err))
+	}
+
+	err := h.ruleService.DeleteRulesByGuidSensor(reqDeleteRuleDto.InputGuid)
+
+	return h.reportRuleResult(service.RULE_ACTION_DELETE, reqDeleteRuleDto.InputGuid, nil, err)
+}
+
+func (h *ConsumerHandler) reportRuleResult(action, inputGuid string, rules []dto.ResponseRuleDto, cause error) error {
+	if err := h.ruleService.PublishRuleResult(action, inputGuid, rules, cause); err != nil {
+		log.Errorf("Error queueing rule result to cloud: %v 💥", err)
+	}
+
+	var fiberErr *fiber.Error
+
+	if errors.As(cause, &fiberErr) {
+		log.Warnf("Rule %s for %s rejected: %s ⚠️", action, inputGuid, fiberErr.Message)
+		return nil
+	}
+
+	return cause
+}
+
+func (h *ConsumerHandler) ControlHandler(message []byte) error {
 	var controlDeviceDto dto.ControlLocalDto
 
 	if err := json.Unmarshal(message, &controlDeviceDto); err != nil {
 		log.Errorf("Failed to unmarshal control message: %v", err)
-		return
+		return fmt.Errorf("invalid control message: %w", err)
 	}
 
 	if err := validate.Struct(controlDeviceDto); err != nil {
 		log.Errorf("Validation error: %v", err)
-		return
+		return fmt.Errorf("invalid control message: %w", err)
 	}
 
-	h.controlDeviceService.ControlDeviceCloud(&controlDeviceDto)
+	return h.controlDeviceService.ControlDeviceCloud(&controlDeviceDto)
 }
 
-func (h *ConsumerHandler) ControlGasDetectorHandler(message []byte) {
+func (h *ConsumerHandler) ControlGasDetectorHandler(message []byte) error {
 	var controlGassDto dto.ControlGasDetector
 
 	if err := json.Unmarshal(message, &controlGassDto); err != nil {
 		log.Errorf("Failed to unmarshal control message: %v", err)
-		return
+		return fmt.Errorf("invalid gas detector message: %w", err)
 	}
 
 	if err := validate.Struct(controlGassDto); err != nil {
 		log.Errorf("Validation error: %v", err)
-		return
+		return fmt.Errorf("invalid gas detector message: %w", err)
 	}
 
-	log.Infof("Data %s", controlGassDto.Condition)
+	h.presenceService.Touch(controlGassDto.Guid)
+
+	log.Infof("Data %d", controlGassDto.Condition)
+
+	return nil
 }
 
-func (h *ConsumerHandler) ControlSensorHandler(message []byte) {
-	messageString := string(message)
+func (h *ConsumerHandler) ControlSensorHandler(codecName string) messagebroker.MessageHandler {
+	return func(message []byte) error {
+		telemetry, err := h.decodeTelemetry(codecName, message)
 
-	guid := strings.Split(messageString, "#")[0]
-	value := strings.Split(messageString, "#")[1]
+		if err != nil {
+			return fmt.Errorf("invalid sensor message: %w", err)
+		}
 
-	h.controlDeviceService.ControlSensor(guid, value)
+		h.presenceService.Touch(telemetry.Guid)
+
+		for _, reading := range telemetry.Readings {
+			if err := h.controlDeviceService.ControlSensor(telemetry.Guid, reading.Value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
 }
 
-func (h *ConsumerHandler) DeleteDeviceFromCloudHandler(message []byte) {
+func (h *ConsumerHandler) DeleteDeviceFromCloudHandler(message []byte) error {
 	var deleteDeviceDtoFromCloud dto.ReqDeleteDeviceFromCloudDto
 
 	if err := json.Unmarshal(message, &deleteDeviceDtoFromCloud); err != nil {
 		log.Errorf("Failed to unmarshal delete device message: %v", err)
-		return
+		return fmt.Errorf("invalid delete device message: %w", err)
 	}
 
-	h.deviceService.DeleteDeviceRMQ(deleteDeviceDtoFromCloud.Guid)
+	if err := validate.Struct(deleteDeviceDtoFromCloud); err != nil {
+		log.Errorf("Validation error: %v", err)
+		return fmt.Errorf("invalid delete device message: %w", err)
+	}
+
+	return h.deviceService.DeleteDeviceRMQ(deleteDeviceDtoFromCloud.Guid)
 }
 
-func (h *ConsumerHandler) MonitoringDataDevice(message []byte) {
-	messageString := string(message)
-	guid := strings.Split(messageString, "#")[0]
-	data := strings.Split(messageString, "#")[1]
+func (h *ConsumerHandler) FloorFromCloudHandler(message []byte) error {
+	var reqCloudFloorDto dto.ReqCloudFloorDto
 
-	h.deviceService.UpdateStatusAsMonitoring(guid, data)
+	if err := json.Unmarshal(message, &reqCloudFloorDto); err != nil {
+		log.Errorf("Failed to unmarshal floor message: %v", err)
+		return fmt.Errorf("invalid floor message: %w", err)
+	}
+
+	if err := validate.Struct(reqCloudFloorDto); err != nil {
+		log.Errorf("Validation error: %v", err)
+		return fmt.Errorf("invalid floor message: %w", err)
+	}
+
+	return h.floorService.ApplyCloudFloor(&reqCloudFloorDto)
 }
 
-func (h *ConsumerHandler) TestingConsumeAktuator(message []byte) {
-	messageString := string(message)
+func (h *ConsumerHandler) RoomFromCloudHandler(message []byte) error {
+	var reqCloudRoomDto dto.ReqCloudRoomDto
+
+	if err := json.Unmarshal(message, &reqCloudRoomDto); err != nil {
+		log.Errorf("Failed to unmarshal room message: %v", err)
+		return fmt.Errorf("invalid room message: %w", err)
+	}
+
+	if err := validate.Struct(reqCloudRoomDto); err != nil {
+		log.Errorf("Validation error: %v", err)
+		return fmt.Errorf("invalid room message: %w", err)
+	}
+
+	return h.roomService.ApplyCloudRoom(&reqCloudRoomDto)
+}
+
+func (h *ConsumerHandler) FloorControlFromCloudHandler(message []byte) error {
+	return h.bulkControlFromCloud(service.BULK_SCOPE_FLOOR, message, h.floorService.ControlCloudFloor)
+}
+
+func (h *ConsumerHandler) RoomControlFromCloudHandler(message []byte) error {
+	return h.bulkControlFromCloud(service.BULK_SCOPE_ROOM, message, h.roomService.ControlCloudRoom)
+}
+
+func (h *ConsumerHandler) bulkControlFromCloud(
+	scope string,
+	message []byte,
+	control func(*dto.ReqCloudBulkControlDto) (*dto.ResponseBulkControlDto, error),
+) error {
+	var reqDto dto.ReqCloudBulkControlDto
+
+	if err := json.Unmarshal(message, &reqDto); err != nil {
+		log.Errorf("Failed to unmarshal %s control message: %v", scope, err)
+		return h.reportBulkResult(scope, "", nil, fmt.Errorf("invalid %s control message: %w", scope, err))
+	}
+
+	if err := validate.Struct(reqDto); err != nil {
+		log.Errorf("Validation error: %v", err)
+		return h.reportBulkResult(scope, reqDto.Guid, nil, fmt.Errorf("invalid %s control message: %w", scope, err))
+	}
+
+	response, err := control(&reqDto)
+
+	return h.reportBulkResult(scope, reqDto.Guid, response, err)
+}
+
+func (h *ConsumerHandler) reportBulkResult(scope, guid string, response *dto.ResponseBulkControlDto, cause error) error {
+	if err := h.controlDeviceService.PublishBulkResult(scope, guid, response, cause); err != nil {
+		log.Errorf("Error queueing %s control result to cloud: %v 💥", scope, err)
+	}
+
+	var fiberErr *fiber.Error
+
+	if errors.As(cause, &fiberErr) {
+		log.Warnf("Control of %s %s rejected: %s ⚠️", scope, guid, fiberErr.Message)
+		return nil
+	}
+
+	return cause
+}
+
+func (h *ConsumerHandler) MonitoringDataDevice(codecName string) messagebroker.MessageHandler {
+	return func(message []byte) error {
+		telemetry, err := h.decodeTelemetry(codecName, message)
+
+		if err != nil {
+			return fmt.Errorf("invalid monitoring message: %w", err)
+		}
+
+		h.presenceService.Touch(telemetry.Guid)
+
+		return h.deviceService.UpdateStatusAsMonitoring(telemetry)
+	}
+}
+
+func (h *ConsumerHandler) decodeTelemetry(codecName string, message []byte) (*codec.Telemetry, error) {
+	if codecName == codec.CODEC_AUTO {
+		if guid, _, found := strings.Cut(string(message), "#"); found &&
+			h.deviceService.PayloadCodec(guid) == enum.PAYLOAD_CODEC_CBOR {
+			codecName = codec.CODEC_CBOR
+		}
+	}
+
+	return codec.DecodeAs(codecName, message)
+}
+
+func (h *ConsumerHandler) AktuatorHandler(message []byte) error {
+	command, err := codec.DecodeCommand(message)
+
+	if err != nil {
+		return fmt.Errorf("invalid aktuator acknowledgement: %w", err)
+	}
+
+	if command.Status == "" {
+		return nil
+	}
+
+	if command.Guid == "" || command.Value == "" || command.CorrelationID == "" {
+		return fmt.Errorf("invalid aktuator acknowledgement %q, expected guid#value#id#ACK", string(message))
+	}
+
+	h.presenceService.Touch(command.Guid)
+
+	switch strings.ToUpper(command.Status) {
+	case "ACK":
+		return h.commandService.Confirm(command.Guid, command.Channel, command.Value, command.CorrelationID, true)
+	case "NACK":
+		return h.commandService.Confirm(command.Guid, command.Channel, command.Value, command.CorrelationID, false)
+	default:
+		return fmt.Errorf("invalid aktuator acknowledgement status %q", command.Status)
+	}
+}
+
+func (h *ConsumerHandler) SyncHandler(message []byte) error {
+	var syncDto dto.ReqSyncDto
+
+	if err := json.Unmarshal(message, &syncDto); err != nil {
+		log.Errorf("Failed to unmarshal sync message: %v", err)
+		return fmt.Errorf("invalid sync message: %w", err)
+	}
+
+	if syncDto.MacServer != "" && syncDto.MacServer != config.MAC_ADDRESS.GetValue() {
+		return fmt.Errorf("sync message is for %s, not this server", syncDto.MacServer)
+	}
+
+	if syncDto.Digest == "" && syncDto.Items == nil && syncDto.Devices == nil {
+		_, err := h.syncService.PublishSnapshot()
+		return err
+	}
+
+	_, err := h.syncService.Reconcile(&syncDto)
 
-	log.Info(messageString)
+	return err
 }
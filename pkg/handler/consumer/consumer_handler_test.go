@@ -0,0 +1,250 @@
+package consumer
+
+import (
+	"encoding/json"
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	messagebroker "go/hioto/pkg/handler/message_broker"
+	"go/hioto/pkg/model"
+	"go/hioto/pkg/service"
+	"go/hioto/pkg/utils"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testConsumer struct {
+	db          *gorm.DB
+	localBroker *messagebroker.MemoryBroker
+	handler     *ConsumerHandler
+}
+
+func newTestConsumer(t *testing.T, ackRequired string) *testConsumer {
+	t.Helper()
+
+	t.Setenv("MAC_ADDRESS", "server-1")
+	t.Setenv("RMQ_CLOUD_INSTANCE", "cloud")
+	t.Setenv("EXCHANGE_DIRECT", "amq.direct")
+	t.Setenv("AKTUATOR_TOPIC", "aktuator")
+	t.Setenv("AKTUATOR_ACK_REQUIRED", ackRequired)
+	t.Setenv("RULES_RESPONSE_QUEUE", "rules_response")
+	t.Setenv("RULES_RESULT_QUEUE", "rules_result")
+	t.Setenv("UPDATE_RES_CLOUD", "update_res")
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	utils.AutoMigrateDb(db)
+
+	localBroker := messagebroker.NewMemoryBroker("local")
+	eventBus := service.NewDeviceEventBus()
+	outboxService := service.NewOutboxService(db, localBroker, messagebroker.NewMemoryBroker("cloud"))
+	commandService := service.NewCommandService(db, localBroker)
+	controlDeviceService := service.NewControlDeviceService(db, commandService, outboxService, eventBus)
+	deviceService := service.NewDeviceService(db, outboxService, eventBus)
+
+	handler := NewConsumerHandler(
+		service.NewRuleService(db, outboxService),
+		deviceService,
+		controlDeviceService,
+		commandService,
+		service.NewIdempotencyService(db),
+		service.NewSyncService(db, deviceService, outboxService),
+		service.NewFloorService(db, outboxService, controlDeviceService),
+		service.NewRoomService(db, outboxService, controlDeviceService),
+		service.NewPresenceService(db, outboxService, eventBus),
+	)
+
+	for _, guid := range []string{"sensor-1", "relay-1", "relay-2"} {
+		deviceType := enum.AKTUATOR
+
+		if guid == "sensor-1" {
+			deviceType = enum.SENSOR
+		}
+
+		if err := db.Create(&model.Registration{
+			Guid:     guid,
+			Mac:      guid + "-mac",
+			Type:     deviceType,
+			Name:     guid,
+			Quantity: 1,
+			Status:   "0",
+		}).Error; err != nil {
+			t.Fatalf("failed to create device %s: %v", guid, err)
+		}
+	}
+
+	return &testConsumer{db: db, localBroker: localBroker, handler: handler}
+}
+
+func (c *testConsumer) outbox(t *testing.T, queue string) []model.Outbox {
+	t.Helper()
+
+	var entries []model.Outbox
+
+	if err := c.db.Where("queue_name = ?", queue).Order("id ASC").Find(&entries).Error; err != nil {
+		t.Fatalf("failed to read outbox: %v", err)
+	}
+
+	return entries
+}
+
+func mustJson(t *testing.T, value any) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(value)
+
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	return body
+}
+
+func TestControlHandler(t *testing.T) {
+	c := newTestConsumer(t, "false")
+
+	if err := c.handler.ControlHandler(mustJson(t, dto.ControlLocalDto{Type: enum.AKTUATOR, Message: "relay-1#1"})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var command model.AktuatorCommand
+
+	c.db.First(&command)
+
+	if command.Status != enum.COMMAND_SENT {
+		t.Errorf("command status = %s, want %s", command.Status, enum.COMMAND_SENT)
+	}
+
+	if published := c.localBroker.PublishedTo("aktuator"); len(published) != 1 || string(published[0].Body) != "relay-1#1" {
+		t.Errorf("published = %v, want relay-1#1", published)
+	}
+
+	var device model.Registration
+
+	c.db.Where("guid = ?", "relay-1").First(&device)
+
+	if device.Status != "1" {
+		t.Errorf("device status = %q, want 1", device.Status)
+	}
+
+	if entries := c.outbox(t, "update_res"); len(entries) != 0 {
+		t.Errorf("cloud control should not echo an update, got %d", len(entries))
+	}
+
+	for _, message := range []string{`{"type":"AKTUATOR"}`, `{"type":"AKTUATOR","message":"missing#1"}`, `not json`} {
+		if err := c.handler.ControlHandler([]byte(message)); err == nil {
+			t.Errorf("%s: expected an error", message)
+		}
+	}
+}
+
+func TestAktuatorHandlerConfirmsCommand(t *testing.T) {
+	c := newTestConsumer(t, "true")
+
+	if err := c.handler.ControlHandler(mustJson(t, dto.ControlLocalDto{Type: enum.AKTUATOR, Message: "relay-1#1"})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var command model.AktuatorCommand
+
+	c.db.First(&command)
+
+	published := c.localBroker.PublishedTo("aktuator")
+
+	if len(published) != 1 || string(published[0].Body) != "relay-1#1#"+command.CorrelationID {
+		t.Fatalf("published = %v, want relay-1#1#%s", published, command.CorrelationID)
+	}
+
+	if err := c.handler.AktuatorHandler([]byte("relay-1#1#" + command.CorrelationID + "#ACK")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.db.First(&command)
+
+	if command.Status != enum.COMMAND_CONFIRMED {
+		t.Errorf("command status = %s, want %s", command.Status, enum.COMMAND_CONFIRMED)
+	}
+
+	var device model.Registration
+
+	c.db.Where("guid = ?", "relay-1").First(&device)
+
+	if device.Status != "1" {
+		t.Errorf("device status = %q, want 1", device.Status)
+	}
+
+	if err := c.handler.AktuatorHandler([]byte("relay-1#1#" + command.CorrelationID + "#MAYBE")); err == nil {
+		t.Error("expected an error for an unknown status")
+	}
+}
+
+func TestRulesHandlers(t *testing.T) {
+	c := newTestConsumer(t, "false")
+
+	if err := c.handler.RulesHandler(mustJson(t, dto.CreateRuleDto{InputGuid: "sensor-1", OutputGuid: []string{"relay-1"}})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if entries := c.outbox(t, "rules_response"); len(entries) != 1 {
+		t.Errorf("rules response entries = %d, want 1", len(entries))
+	}
+
+	request := dto.ReqCloudRuleDto{
+		CreateRuleDto: dto.CreateRuleDto{InputGuid: "sensor-1", OutputGuid: []string{"relay-1", "relay-2"}},
+		Replace:       true,
+	}
+
+	if err := c.handler.RulesFromCloudHandler(mustJson(t, request)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var count int64
+
+	c.db.Model(&model.RuleDevice{}).Where("input_guid = ?", "sensor-1").Count(&count)
+
+	if count != 8 {
+		t.Errorf("rules = %d, want 8", count)
+	}
+
+	if entries := c.outbox(t, "rules_response"); len(entries) != 1 {
+		t.Errorf("cloud rules should not be echoed on the legacy queue, got %d entries", len(entries))
+	}
+
+	request.OutputGuid = []string{"missing"}
+
+	if err := c.handler.RulesFromCloudHandler(mustJson(t, request)); err != nil {
+		t.Fatalf("rejected rules should be reported, got %v", err)
+	}
+
+	entries := c.outbox(t, "rules_result")
+
+	if len(entries) != 2 {
+		t.Fatalf("rules result entries = %d, want 2", len(entries))
+	}
+
+	var results [2]dto.ResponseRuleResultDto
+
+	for i, entry := range entries {
+		if err := json.Unmarshal([]byte(entry.Payload), &results[i]); err != nil {
+			t.Fatalf("invalid rule result: %v", err)
+		}
+	}
+
+	if !results[0].Success || results[0].Action != service.RULE_ACTION_REPLACE || len(results[0].Rules) != 8 {
+		t.Errorf("first result = %+v", results[0])
+	}
+
+	if results[1].Success || results[1].Error == "" {
+		t.Errorf("second result = %+v", results[1])
+	}
+}
@@ -0,0 +1,92 @@
+package consumer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	messagebroker "go/hioto/pkg/handler/message_broker"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+const envelopeVersion = 1
+
+func parseEnvelope(message []byte) (*dto.EnvelopeDto, bool) {
+	trimmed := bytes.TrimSpace(message)
+
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+
+	var envelope dto.EnvelopeDto
+
+	if err := json.Unmarshal(trimmed, &envelope); err != nil {
+		return nil, false
+	}
+
+	if envelope.ID == "" || len(envelope.Payload) == 0 {
+		return nil, false
+	}
+
+	return &envelope, true
+}
+
+func envelopePayload(envelope *dto.EnvelopeDto) ([]byte, error) {
+	payload := bytes.TrimSpace(envelope.Payload)
+
+	if len(payload) > 0 && payload[0] == '"' {
+		var text string
+
+		if err := json.Unmarshal(payload, &text); err != nil {
+			return nil, fmt.Errorf("invalid envelope payload: %w", err)
+		}
+
+		return []byte(text), nil
+	}
+
+	return payload, nil
+}
+
+func (h *ConsumerHandler) Envelope(messageType enum.EMessageType, handler messagebroker.MessageHandler) messagebroker.MessageHandler {
+	return func(message []byte) error {
+		envelope, ok := parseEnvelope(message)
+
+		if !ok {
+			return handler(message)
+		}
+
+		if envelope.Version > envelopeVersion {
+			return fmt.Errorf("unsupported envelope version %d for message %s", envelope.Version, envelope.ID)
+		}
+
+		if envelope.Type != "" && envelope.Type != messageType {
+			return fmt.Errorf("envelope %s has type %s, expected %s", envelope.ID, envelope.Type, messageType)
+		}
+
+		payload, err := envelopePayload(envelope)
+
+		if err != nil {
+			return err
+		}
+
+		claimed, err := h.idempotencyService.Claim(envelope.ID, messageType, envelope.IssuedAt)
+
+		if err != nil {
+			return fmt.Errorf("failed to check message %s: %w", envelope.ID, err)
+		}
+
+		if !claimed {
+			log.Warnf("Duplicate %s message %s skipped ♻️", messageType, envelope.ID)
+			return nil
+		}
+
+		if err := handler(payload); err != nil {
+			h.idempotencyService.Release(envelope.ID)
+			return err
+		}
+
+		return nil
+	}
+}
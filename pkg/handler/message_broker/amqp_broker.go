@@ -0,0 +1,49 @@
+package messagebroker
+
+import (
+	"context"
+	"go/hioto/config"
+)
+
+type AmqpBroker struct {
+	instanceName string
+	exchange     string
+}
+
+func NewAmqpBroker(instanceName, exchange string) *AmqpBroker {
+	return &AmqpBroker{instanceName: instanceName, exchange: exchange}
+}
+
+func (b *AmqpBroker) Name() string {
+	return b.instanceName
+}
+
+func (b *AmqpBroker) IsConnected() bool {
+	instance, err := config.GetRMQInstance(b.instanceName)
+
+	return err == nil && instance.IsConnected()
+}
+
+func (b *AmqpBroker) Publish(ctx context.Context, destination string, payload []byte, opts ...PublishOption) error {
+	options := applyPublishOptions(opts)
+
+	if options.Exchange == "" {
+		options.Exchange = b.exchange
+	}
+
+	return PublishToRmq(b.instanceName, payload, destination, options.Exchange)
+}
+
+func (b *AmqpBroker) Subscribe(ctx context.Context, destination string, handler DeliveryHandler) error {
+	go ConsumeRmq(ctx, b.instanceName, b.exchange, destination, handler)
+
+	return nil
+}
+
+func (b *AmqpBroker) Ack(delivery *Delivery) error {
+	if delivery.ack == nil {
+		return nil
+	}
+
+	return delivery.ack()
+}
@@ -0,0 +1,110 @@
+package messagebroker
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+var ErrBrokerUnavailable = errors.New("broker is not connected")
+
+type Delivery struct {
+	Destination string
+	Body        []byte
+	ack         func() error
+}
+
+func NewDelivery(destination string, body []byte, ack func() error) *Delivery {
+	return &Delivery{Destination: destination, Body: body, ack: ack}
+}
+
+type DeliveryHandler func(*Delivery) error
+
+type PublishOptions struct {
+	Exchange string
+	QoS      byte
+	Retain   bool
+}
+
+type PublishOption func(*PublishOptions)
+
+func WithExchange(exchange string) PublishOption {
+	return func(o *PublishOptions) {
+		o.Exchange = exchange
+	}
+}
+
+func WithQoS(qos byte) PublishOption {
+	return func(o *PublishOptions) {
+		o.QoS = qos
+	}
+}
+
+func WithRetain() PublishOption {
+	return func(o *PublishOptions) {
+		o.Retain = true
+	}
+}
+
+func applyPublishOptions(opts []PublishOption) PublishOptions {
+	var options PublishOptions
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+type Broker interface {
+	Name() string
+	IsConnected() bool
+	Publish(ctx context.Context, destination string, payload []byte, opts ...PublishOption) error
+	Subscribe(ctx context.Context, destination string, handler DeliveryHandler) error
+	Ack(delivery *Delivery) error
+}
+
+func BodyHandler(handler MessageHandler) DeliveryHandler {
+	return func(delivery *Delivery) error {
+		return handler(delivery.Body)
+	}
+}
+
+func AckAfter(broker Broker, handler DeliveryHandler) DeliveryHandler {
+	return func(delivery *Delivery) error {
+		if err := handler(delivery); err != nil {
+			log.Errorf("[%s] Failed to handle message on %s, leaving it unacked: %v 💥", broker.Name(), delivery.Destination, err)
+			return err
+		}
+
+		if err := broker.Ack(delivery); err != nil {
+			log.Errorf("[%s] Failed to ack message on %s: %v", broker.Name(), delivery.Destination, err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+func MatchTopic(filter, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+
+	for i, level := range filterLevels {
+		if level == "#" {
+			return true
+		}
+
+		if i >= len(topicLevels) {
+			return false
+		}
+
+		if level != "+" && level != topicLevels[i] {
+			return false
+		}
+	}
+
+	return len(filterLevels) == len(topicLevels)
+}
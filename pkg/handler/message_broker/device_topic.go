@@ -0,0 +1,59 @@
+package messagebroker
+
+import (
+	"go/hioto/config"
+	"strings"
+)
+
+const defaultDeviceTopicPrefix = "hioto"
+
+const (
+	DEVICE_TOPIC_STATE     = "state"
+	DEVICE_TOPIC_SET       = "set"
+	DEVICE_TOPIC_TELEMETRY = "telemetry"
+	DEVICE_TOPIC_ACK       = "ack"
+)
+
+func DeviceTopicPrefix() string {
+	if prefix := config.MQTT_DEVICE_TOPIC_PREFIX.GetValue(); prefix != "" {
+		return strings.TrimSuffix(prefix, "/")
+	}
+
+	return defaultDeviceTopicPrefix
+}
+
+func DeviceTopic(guid, kind string) string {
+	return strings.Join([]string{DeviceTopicPrefix(), config.MAC_ADDRESS.GetValue(), guid, kind}, "/")
+}
+
+func DeviceTopicFilter(kind string) string {
+	return DeviceTopic("+", kind)
+}
+
+func ParseDeviceTopic(topic string) (string, string, bool) {
+	prefix := DeviceTopicPrefix() + "/"
+
+	if !strings.HasPrefix(topic, prefix) {
+		return "", "", false
+	}
+
+	levels := strings.Split(strings.TrimPrefix(topic, prefix), "/")
+
+	if len(levels) != 3 || levels[0] == "" || levels[1] == "" || levels[2] == "" {
+		return "", "", false
+	}
+
+	return levels[1], levels[2], true
+}
+
+func DeviceTopicHandler(handler MessageHandler) DeliveryHandler {
+	return func(delivery *Delivery) error {
+		guid, _, ok := ParseDeviceTopic(delivery.Destination)
+
+		if !ok {
+			return handler(delivery.Body)
+		}
+
+		return handler(append([]byte(guid+"#"), delivery.Body...))
+	}
+}
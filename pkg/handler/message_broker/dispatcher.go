@@ -0,0 +1,323 @@
+package messagebroker
+
+import (
+	"context"
+	"errors"
+	"go/hioto/config"
+	"go/hioto/pkg/codec"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type OverflowPolicy string
+
+const (
+	OVERFLOW_BLOCK       OverflowPolicy = "block"
+	OVERFLOW_DROP_NEWEST OverflowPolicy = "drop_newest"
+	OVERFLOW_DROP_OLDEST OverflowPolicy = "drop_oldest"
+)
+
+const (
+	defaultDispatcherWorkers   = 4
+	defaultDispatcherQueueSize = 100
+)
+
+var ErrDispatcherStopped = errors.New("dispatcher is stopped")
+
+type DispatcherStats struct {
+	Route          string         `json:"route"`
+	Workers        int            `json:"workers"`
+	QueueSize      int            `json:"queue_size"`
+	QueueDepth     int            `json:"queue_depth"`
+	MaxShardDepth  int            `json:"max_shard_depth"`
+	Policy         OverflowPolicy `json:"policy"`
+	Processed      uint64         `json:"processed"`
+	Failed         uint64         `json:"failed"`
+	Dropped        uint64         `json:"dropped"`
+	AvgWaitMs      float64        `json:"avg_wait_ms"`
+	AvgHandleMs    float64        `json:"avg_handle_ms"`
+	MaxLatencyMs   float64        `json:"max_latency_ms"`
+	LastLatencyMs  float64        `json:"last_latency_ms"`
+	LastActivityAt *time.Time     `json:"last_activity_at"`
+}
+
+type dispatchJob struct {
+	delivery   *Delivery
+	enqueuedAt time.Time
+}
+
+type Dispatcher struct {
+	route   string
+	handler DeliveryHandler
+	policy  OverflowPolicy
+	size    int
+	shards  []chan dispatchJob
+	stopped atomic.Bool
+	done    chan struct{}
+
+	mu           sync.Mutex
+	enqueueMu    []sync.Mutex
+	processed    uint64
+	failed       uint64
+	dropped      uint64
+	totalWait    time.Duration
+	totalHandle  time.Duration
+	maxLatency   time.Duration
+	lastLatency  time.Duration
+	lastActivity *time.Time
+}
+
+var (
+	dispatchers   = make(map[string]*Dispatcher)
+	dispatchersMu sync.Mutex
+)
+
+func NewDispatcher(route string, workers, queueSize int, policy OverflowPolicy, handler DeliveryHandler) *Dispatcher {
+	if workers <= 0 {
+		workers = defaultDispatcherWorkers
+	}
+
+	if queueSize <= 0 {
+		queueSize = defaultDispatcherQueueSize
+	}
+
+	switch policy {
+	case OVERFLOW_BLOCK, OVERFLOW_DROP_NEWEST, OVERFLOW_DROP_OLDEST:
+	default:
+		policy = OVERFLOW_BLOCK
+	}
+
+	d := &Dispatcher{
+		route:     route,
+		handler:   handler,
+		policy:    policy,
+		size:      queueSize,
+		shards:    make([]chan dispatchJob, workers),
+		enqueueMu: make([]sync.Mutex, workers),
+		done:      make(chan struct{}),
+	}
+
+	for i := range d.shards {
+		d.shards[i] = make(chan dispatchJob, queueSize)
+	}
+
+	dispatchersMu.Lock()
+	dispatchers[route] = d
+	dispatchersMu.Unlock()
+
+	return d
+}
+
+func NewDispatcherFromEnv(route string, handler DeliveryHandler) *Dispatcher {
+	workers, _ := strconv.Atoi(config.MQTT_WORKER_COUNT.GetValue())
+	queueSize, _ := strconv.Atoi(config.MQTT_WORKER_QUEUE_SIZE.GetValue())
+	policy := OverflowPolicy(strings.ToLower(config.MQTT_OVERFLOW_POLICY.GetValue()))
+
+	return NewDispatcher(route, workers, queueSize, policy, handler)
+}
+
+func (d *Dispatcher) Start(ctx context.Context) {
+	for _, shard := range d.shards {
+		go d.work(ctx, shard)
+	}
+
+	go func() {
+		<-ctx.Done()
+		d.stopped.Store(true)
+		close(d.done)
+	}()
+}
+
+func (d *Dispatcher) work(ctx context.Context, shard chan dispatchJob) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-shard:
+			d.run(job)
+		}
+	}
+}
+
+func (d *Dispatcher) run(job dispatchJob) {
+	started := time.Now()
+
+	err := d.safeHandle(job.delivery)
+
+	finished := time.Now()
+	wait := started.Sub(job.enqueuedAt)
+	handle := finished.Sub(started)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.processed++
+	if err != nil {
+		d.failed++
+	}
+	d.totalWait += wait
+	d.totalHandle += handle
+	d.lastLatency = wait + handle
+	if d.lastLatency > d.maxLatency {
+		d.maxLatency = d.lastLatency
+	}
+	d.lastActivity = &finished
+}
+
+func (d *Dispatcher) safeHandle(delivery *Delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("[%s] Dispatcher handler panic: %v 💥", d.route, r)
+			err = errors.New("handler panic")
+		}
+	}()
+
+	if err := d.handler(delivery); err != nil {
+		log.Errorf("[%s] Failed to handle message: %v 💥", d.route, err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) Dispatch(delivery *Delivery) error {
+	if d.stopped.Load() {
+		return ErrDispatcherStopped
+	}
+
+	index := d.shardIndex(ShardKey(delivery))
+	shard := d.shards[index]
+	job := dispatchJob{delivery: delivery, enqueuedAt: time.Now()}
+
+	switch d.policy {
+	case OVERFLOW_DROP_NEWEST:
+		select {
+		case shard <- job:
+		default:
+			d.drop(delivery)
+		}
+	case OVERFLOW_DROP_OLDEST:
+		d.enqueueMu[index].Lock()
+		defer d.enqueueMu[index].Unlock()
+
+		for {
+			select {
+			case shard <- job:
+				return nil
+			default:
+			}
+
+			select {
+			case oldest := <-shard:
+				d.drop(oldest.delivery)
+			default:
+			}
+		}
+	default:
+		select {
+		case shard <- job:
+		case <-d.done:
+			return ErrDispatcherStopped
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) drop(delivery *Delivery) {
+	d.mu.Lock()
+	d.dropped++
+	d.mu.Unlock()
+
+	log.Warnf("[%s] Queue full, message on %s dropped (%s) ⚠️", d.route, delivery.Destination, d.policy)
+
+	if delivery.ack != nil {
+		if err := delivery.ack(); err != nil {
+			log.Errorf("[%s] Failed to ack dropped message: %v", d.route, err)
+		}
+	}
+}
+
+func (d *Dispatcher) shardIndex(key string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+
+	return int(hash.Sum32() % uint32(len(d.shards)))
+}
+
+func (d *Dispatcher) Stats() DispatcherStats {
+	depth, maxDepth := 0, 0
+
+	for _, shard := range d.shards {
+		depth += len(shard)
+
+		if len(shard) > maxDepth {
+			maxDepth = len(shard)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := DispatcherStats{
+		Route:          d.route,
+		Workers:        len(d.shards),
+		QueueSize:      d.size,
+		QueueDepth:     depth,
+		MaxShardDepth:  maxDepth,
+		Policy:         d.policy,
+		Processed:      d.processed,
+		Failed:         d.failed,
+		Dropped:        d.dropped,
+		MaxLatencyMs:   durationMs(d.maxLatency),
+		LastLatencyMs:  durationMs(d.lastLatency),
+		LastActivityAt: d.lastActivity,
+	}
+
+	if d.processed > 0 {
+		stats.AvgWaitMs = durationMs(d.totalWait) / float64(d.processed)
+		stats.AvgHandleMs = durationMs(d.totalHandle) / float64(d.processed)
+	}
+
+	return stats
+}
+
+func durationMs(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+func GetDispatcherStats() []DispatcherStats {
+	dispatchersMu.Lock()
+	defer dispatchersMu.Unlock()
+
+	stats := make([]DispatcherStats, 0, len(dispatchers))
+
+	for _, d := range dispatchers {
+		stats = append(stats, d.Stats())
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Route < stats[j].Route
+	})
+
+	return stats
+}
+
+func ShardKey(delivery *Delivery) string {
+	if guid, _, ok := ParseDeviceTopic(delivery.Destination); ok {
+		return guid
+	}
+
+	if guid := codec.PeekGuid(delivery.Body); guid != "" {
+		return guid
+	}
+
+	return delivery.Destination
+}
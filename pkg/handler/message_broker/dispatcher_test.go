@@ -0,0 +1,187 @@
+package messagebroker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+func TestDispatcherOverflow(t *testing.T) {
+	tests := []struct {
+		policy  OverflowPolicy
+		kept    string
+		dropped uint64
+		acked   []string
+	}{
+		{policy: OVERFLOW_DROP_NEWEST, kept: "1", dropped: 2, acked: []string{"2", "3"}},
+		{policy: OVERFLOW_DROP_OLDEST, kept: "3", dropped: 2, acked: []string{"1", "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			var acked []string
+
+			d := NewDispatcher("test/"+string(tt.policy), 1, 1, tt.policy, func(*Delivery) error { return nil })
+
+			for _, body := range []string{"1", "2", "3"} {
+				delivery := &Delivery{Destination: "test", Body: []byte(body)}
+				delivery.ack = func() error {
+					acked = append(acked, string(delivery.Body))
+					return nil
+				}
+
+				if err := d.Dispatch(delivery); err != nil {
+					t.Fatalf("dispatch %s: %v", body, err)
+				}
+			}
+
+			stats := d.Stats()
+
+			if stats.Dropped != tt.dropped || stats.QueueDepth != 1 {
+				t.Fatalf("dropped = %d, depth = %d, want %d and 1", stats.Dropped, stats.QueueDepth, tt.dropped)
+			}
+
+			if job := <-d.shards[0]; string(job.delivery.Body) != tt.kept {
+				t.Errorf("kept %q, want %q", job.delivery.Body, tt.kept)
+			}
+
+			if len(acked) != len(tt.acked) || acked[0] != tt.acked[0] || acked[1] != tt.acked[1] {
+				t.Errorf("acked = %v, want %v", acked, tt.acked)
+			}
+		})
+	}
+}
+
+func TestDispatcherBlock(t *testing.T) {
+	handled := make(chan string, 2)
+
+	d := NewDispatcher("test/block", 1, 1, OVERFLOW_BLOCK, func(delivery *Delivery) error {
+		handled <- string(delivery.Body)
+		return nil
+	})
+
+	if err := d.Dispatch(&Delivery{Destination: "test", Body: []byte("1")}); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- d.Dispatch(&Delivery{Destination: "test", Body: []byte("2")})
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("dispatch should block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d.Start(ctx)
+
+	if err := <-done; err != nil {
+		t.Fatalf("blocked dispatch: %v", err)
+	}
+
+	for _, want := range []string{"1", "2"} {
+		if got := <-handled; got != want {
+			t.Errorf("handled %q, want %q", got, want)
+		}
+	}
+
+	if stats := d.Stats(); stats.Dropped != 0 {
+		t.Errorf("dropped = %d, want 0", stats.Dropped)
+	}
+}
+
+func TestDispatcherStopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := NewDispatcher("test/stopped", 1, 1, OVERFLOW_BLOCK, func(*Delivery) error { return nil })
+	d.Start(ctx)
+	cancel()
+
+	time.Sleep(10 * time.Millisecond)
+
+	if err := d.Dispatch(&Delivery{Destination: "test"}); err != ErrDispatcherStopped {
+		t.Errorf("error = %v, want ErrDispatcherStopped", err)
+	}
+}
+
+func TestDispatcherBlockReleasedOnStop(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := NewDispatcher("test/block-stop", 1, 1, OVERFLOW_BLOCK, func(*Delivery) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	d.Start(ctx)
+
+	for _, body := range []string{"1", "2"} {
+		if err := d.Dispatch(&Delivery{Destination: "test", Body: []byte(body)}); err != nil {
+			t.Fatalf("dispatch %s: %v", body, err)
+		}
+
+		if body == "1" {
+			<-started
+		}
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- d.Dispatch(&Delivery{Destination: "test", Body: []byte("3")})
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != ErrDispatcherStopped {
+			t.Errorf("error = %v, want ErrDispatcherStopped", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("dispatch stayed blocked after the dispatcher stopped")
+	}
+}
+
+func TestShardKey(t *testing.T) {
+	t.Setenv("MAC_ADDRESS", "server-1")
+
+	cborBody, err := cbor.Marshal(map[string]any{"guid": "sensor-3", "values": map[string]any{"gas": 1}})
+
+	if err != nil {
+		t.Fatalf("failed to encode cbor: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		delivery *Delivery
+		want     string
+	}{
+		{name: "device topic", delivery: &Delivery{Destination: "hioto/server-1/relay-1/ack", Body: []byte("1#abc")}, want: "relay-1"},
+		{name: "legacy", delivery: &Delivery{Destination: "monitoring", Body: []byte("sensor-1#0101")}, want: "sensor-1"},
+		{name: "json", delivery: &Delivery{Destination: "monitoring", Body: []byte(`{"guid":"sensor-2","values":{"gas":1}}`)}, want: "sensor-2"},
+		{name: "cbor", delivery: &Delivery{Destination: "monitoring", Body: cborBody}, want: "sensor-3"},
+		{name: "cbor with guid prefix", delivery: &Delivery{Destination: "monitoring", Body: append([]byte("sensor-4#"), cborBody...)}, want: "sensor-4"},
+		{name: "no guid", delivery: &Delivery{Destination: "monitoring", Body: []byte("garbage")}, want: "monitoring"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShardKey(tt.delivery); got != tt.want {
+				t.Errorf("ShardKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
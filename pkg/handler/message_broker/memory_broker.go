@@ -0,0 +1,133 @@
+package messagebroker
+
+import (
+	"context"
+	"sync"
+)
+
+type memorySubscription struct {
+	filter  string
+	handler DeliveryHandler
+}
+
+type MemoryBroker struct {
+	name          string
+	mu            sync.Mutex
+	connected     bool
+	subscriptions []memorySubscription
+	published     []Delivery
+	acked         []Delivery
+}
+
+func NewMemoryBroker(name string) *MemoryBroker {
+	return &MemoryBroker{name: name, connected: true}
+}
+
+func (b *MemoryBroker) Name() string {
+	return b.name
+}
+
+func (b *MemoryBroker) IsConnected() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.connected
+}
+
+func (b *MemoryBroker) SetConnected(connected bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.connected = connected
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, destination string, payload []byte, opts ...PublishOption) error {
+	b.mu.Lock()
+
+	if !b.connected {
+		b.mu.Unlock()
+		return ErrBrokerUnavailable
+	}
+
+	body := append([]byte(nil), payload...)
+	b.published = append(b.published, Delivery{Destination: destination, Body: body})
+
+	var handlers []DeliveryHandler
+
+	for _, subscription := range b.subscriptions {
+		if MatchTopic(subscription.filter, destination) {
+			handlers = append(handlers, subscription.handler)
+		}
+	}
+
+	b.mu.Unlock()
+
+	for _, handler := range handlers {
+		delivery := &Delivery{Destination: destination, Body: body}
+		delivery.ack = func() error {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			b.acked = append(b.acked, Delivery{Destination: delivery.Destination, Body: delivery.Body})
+			return nil
+		}
+
+		handler(delivery)
+	}
+
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, destination string, handler DeliveryHandler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscriptions = append(b.subscriptions, memorySubscription{filter: destination, handler: handler})
+
+	return nil
+}
+
+func (b *MemoryBroker) Ack(delivery *Delivery) error {
+	if delivery.ack == nil {
+		return nil
+	}
+
+	return delivery.ack()
+}
+
+func (b *MemoryBroker) Published() []Delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Delivery(nil), b.published...)
+}
+
+func (b *MemoryBroker) PublishedTo(destination string) []Delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var result []Delivery
+
+	for _, delivery := range b.published {
+		if delivery.Destination == destination {
+			result = append(result, delivery)
+		}
+	}
+
+	return result
+}
+
+func (b *MemoryBroker) Acked() []Delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Delivery(nil), b.acked...)
+}
+
+func (b *MemoryBroker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.published = nil
+	b.acked = nil
+}
@@ -0,0 +1,114 @@
+package messagebroker
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemoryBrokerPublishSubscribeAck(t *testing.T) {
+	broker := NewMemoryBroker("local")
+
+	var received []string
+
+	handler := func(delivery *Delivery) error {
+		received = append(received, delivery.Destination+" "+string(delivery.Body))
+
+		return broker.Ack(delivery)
+	}
+
+	if err := broker.Subscribe(context.Background(), "hioto/+/state", handler); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	for _, destination := range []string{"hioto/relay-1/state", "hioto/relay-1/set", "Aktuator"} {
+		if err := broker.Publish(context.Background(), destination, []byte("1")); err != nil {
+			t.Fatalf("publish to %s: %v", destination, err)
+		}
+	}
+
+	if len(received) != 1 || received[0] != "hioto/relay-1/state 1" {
+		t.Errorf("received = %v, want only hioto/relay-1/state", received)
+	}
+
+	if published := broker.Published(); len(published) != 3 {
+		t.Errorf("published = %d, want 3", len(published))
+	}
+
+	if published := broker.PublishedTo("Aktuator"); len(published) != 1 || string(published[0].Body) != "1" {
+		t.Errorf("published to Aktuator = %v", published)
+	}
+
+	if acked := broker.Acked(); len(acked) != 1 || acked[0].Destination != "hioto/relay-1/state" {
+		t.Errorf("acked = %v, want the delivered message", acked)
+	}
+
+	broker.Reset()
+
+	if len(broker.Published()) != 0 || len(broker.Acked()) != 0 {
+		t.Error("reset should clear the recorded traffic")
+	}
+}
+
+func TestMemoryBrokerDisconnected(t *testing.T) {
+	broker := NewMemoryBroker("cloud")
+	broker.SetConnected(false)
+
+	if broker.IsConnected() {
+		t.Fatal("broker should report disconnected")
+	}
+
+	if err := broker.Publish(context.Background(), "Aktuator", []byte("1")); !errors.Is(err, ErrBrokerUnavailable) {
+		t.Errorf("error = %v, want ErrBrokerUnavailable", err)
+	}
+
+	if len(broker.Published()) != 0 {
+		t.Error("a failed publish should not be recorded")
+	}
+}
+
+func TestAckAfterSkipsFailedMessages(t *testing.T) {
+	broker := NewMemoryBroker("local")
+
+	handler := AckAfter(broker, func(delivery *Delivery) error {
+		if string(delivery.Body) == "bad" {
+			return errors.New("handler failed")
+		}
+
+		return nil
+	})
+
+	if err := broker.Subscribe(context.Background(), "rules", handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, body := range []string{"good", "bad"} {
+		broker.Publish(context.Background(), "rules", []byte(body))
+	}
+
+	if acked := broker.Acked(); len(acked) != 1 || string(acked[0].Body) != "good" {
+		t.Errorf("acked = %v, want only the handled message", acked)
+	}
+}
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		filter string
+		topic  string
+		want   bool
+	}{
+		{filter: "Aktuator", topic: "Aktuator", want: true},
+		{filter: "Aktuator", topic: "Sensor", want: false},
+		{filter: "hioto/+/state", topic: "hioto/relay-1/state", want: true},
+		{filter: "hioto/+/state", topic: "hioto/relay-1/set", want: false},
+		{filter: "hioto/#", topic: "hioto/mac/relay-1/state", want: true},
+		{filter: "hioto/+", topic: "hioto/mac/relay-1", want: false},
+		{filter: "hioto/+/state", topic: "hioto/relay-1", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchTopic(tt.filter, tt.topic); got != tt.want {
+			t.Errorf("MatchTopic(%q, %q) = %v, want %v", tt.filter, tt.topic, got, tt.want)
+		}
+	}
+}
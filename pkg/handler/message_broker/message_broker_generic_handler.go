@@ -11,9 +11,11 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-type MessageHandler func([]byte)
+type MessageHandler func([]byte) error
 
-func ConsumeRmq(ctx context.Context, instanceName, queueName string, handler MessageHandler) {
+const rmqConsumerPrefetch = 20
+
+func ConsumeRmq(ctx context.Context, instanceName, exchange, queueName string, handler DeliveryHandler) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -30,10 +32,10 @@ func ConsumeRmq(ctx context.Context, instanceName, queueName string, handler Mes
 			continue
 		}
 
-		ch := instance.Channel
+		ch, err := instance.Borrow()
 
-		if instance == nil || instance.Channel == nil {
-			log.Warnf("[%s] Channel not ready, retrying...", queueName)
+		if err != nil {
+			log.Warnf("[%s] Channel not ready, retrying...: %v", queueName, err)
 			time.Sleep(5 * time.Second)
 			continue
 		}
@@ -50,27 +52,45 @@ func ConsumeRmq(ctx context.Context, instanceName, queueName string, handler Mes
 		)
 		if err != nil {
 			log.Errorf("[%s] Queue declare error: %v", queueName, err)
+			instance.Return(ch)
 			time.Sleep(5 * time.Second)
 			continue
 		}
 
-		msgs, err := ch.Consume(q.Name, "", true, false, false, false, nil)
+		if exchange != "" {
+			if err := ch.QueueBind(q.Name, queueName, exchange, false, nil); err != nil {
+				log.Errorf("[%s] Queue bind error: %v", queueName, err)
+				instance.Return(ch)
+				time.Sleep(5 * time.Second)
+				continue
+			}
+		}
+
+		if err := ch.Qos(rmqConsumerPrefetch, 0, false); err != nil {
+			log.Errorf("[%s] Failed to set prefetch: %v", queueName, err)
+			instance.Return(ch)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
 		if err != nil {
 			log.Errorf("[%s] Failed to consume: %v", queueName, err)
+			instance.Return(ch)
 			time.Sleep(5 * time.Second)
 			continue
 		}
 
 		log.Infof("[%s] Waiting for messages ⚡️", queueName)
 
-		jobs := make(chan []byte, 100)
+		jobs := make(chan amqp.Delivery, 100)
 		wg := &sync.WaitGroup{}
 		for range 5 {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				for body := range jobs {
-					handler(body)
+				for d := range jobs {
+					handleRmqDelivery(queueName, d, handler)
 				}
 			}()
 		}
@@ -87,7 +107,7 @@ func ConsumeRmq(ctx context.Context, instanceName, queueName string, handler Mes
 					break consumeLoop
 				}
 				select {
-				case jobs <- d.Body:
+				case jobs <- d:
 				case <-ctx.Done():
 					break consumeLoop
 				}
@@ -97,6 +117,12 @@ func ConsumeRmq(ctx context.Context, instanceName, queueName string, handler Mes
 		close(jobs)
 		wg.Wait()
 
+		if !ch.IsClosed() {
+			ch.Close()
+		}
+
+		instance.Return(ch)
+
 		if ctx.Err() != nil {
 			return
 		}
@@ -106,32 +132,68 @@ func ConsumeRmq(ctx context.Context, instanceName, queueName string, handler Mes
 	}
 }
 
+func handleRmqDelivery(queueName string, d amqp.Delivery, handler DeliveryHandler) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("[%s] Handler panic, message rejected: %v 💥", queueName, r)
+
+			if err := d.Nack(false, false); err != nil {
+				log.Errorf("[%s] Failed to reject message: %v", queueName, err)
+			}
+		}
+	}()
+
+	if err := handler(NewDelivery(queueName, d.Body, func() error {
+		return d.Ack(false)
+	})); err != nil {
+		log.Errorf("[%s] Failed to handle message, requeueing: %v 💥", queueName, err)
+
+		if err := d.Nack(false, true); err != nil {
+			log.Errorf("[%s] Failed to requeue message: %v", queueName, err)
+		}
+	}
+}
+
 func ConsumeMQTTTopic(
 	ctx context.Context,
 	instanceName, topic string,
-	handlerFunc MessageHandler,
-) {
+	handlerFunc DeliveryHandler,
+) error {
 	client, err := config.GetMqttInstance(instanceName)
 	if err != nil {
 		log.Errorf("MQTT instance error: %v", err)
-		return
+		return err
+	}
+
+	if err := config.AddMqttSubscription(instanceName, topic); err != nil {
+		return err
 	}
 
-	config.MqttSubscriptions.Store(topic, handlerFunc)
+	dispatcher := NewDispatcherFromEnv(instanceName+"/"+topic, handlerFunc)
+	dispatcher.Start(ctx)
 
 	client.AddRoute(topic, func(client mqtt.Client, msg mqtt.Message) {
 		log.Infof("📥 MQTT [%s]: %s", msg.Topic(), string(msg.Payload()))
-		handlerFunc(msg.Payload())
+		if err := dispatcher.Dispatch(NewDelivery(msg.Topic(), msg.Payload(), func() error {
+			msg.Ack()
+			return nil
+		})); err != nil {
+			log.Errorf("MQTT [%s] failed to dispatch message: %v 💥", msg.Topic(), err)
+		}
 	})
 
 	if client.IsConnected() {
-		if token := client.Subscribe(topic, 0, nil); token.Wait() && token.Error() != nil {
+		if token := client.Subscribe(topic, config.MQTT_SUBSCRIBE_QOS, nil); token.Wait() && token.Error() != nil {
 			log.Errorf("❌ Failed to subscribe '%s': %v", topic, token.Error())
 		} else {
 			log.Infof("🔄 Subscribed (late): %s", topic)
 		}
 	}
 
-	<-ctx.Done()
-	log.Warnf("MQTT consumer stopped for: %s", topic)
+	go func() {
+		<-ctx.Done()
+		log.Warnf("MQTT consumer stopped for: %s", topic)
+	}()
+
+	return nil
 }
@@ -0,0 +1,42 @@
+package messagebroker
+
+import (
+	"context"
+	"go/hioto/config"
+)
+
+type MqttBroker struct {
+	instanceName string
+}
+
+func NewMqttBroker(instanceName string) *MqttBroker {
+	return &MqttBroker{instanceName: instanceName}
+}
+
+func (b *MqttBroker) Name() string {
+	return b.instanceName
+}
+
+func (b *MqttBroker) IsConnected() bool {
+	client, err := config.GetMqttInstance(b.instanceName)
+
+	return err == nil && client.IsConnectionOpen()
+}
+
+func (b *MqttBroker) Publish(ctx context.Context, destination string, payload []byte, opts ...PublishOption) error {
+	options := applyPublishOptions(opts)
+
+	return PublishToMqtt(b.instanceName, destination, payload, options.QoS, options.Retain)
+}
+
+func (b *MqttBroker) Subscribe(ctx context.Context, destination string, handler DeliveryHandler) error {
+	return ConsumeMQTTTopic(ctx, b.instanceName, destination, handler)
+}
+
+func (b *MqttBroker) Ack(delivery *Delivery) error {
+	if delivery.ack == nil {
+		return nil
+	}
+
+	return delivery.ack()
+}
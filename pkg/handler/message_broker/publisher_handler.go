@@ -1,21 +1,41 @@
 package messagebroker
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"go/hioto/config"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-func PublishToRmq(instanceName string, message []byte, queueName string, exchange string) {
+const publishConfirmTimeout = 10 * time.Second
+
+var (
+	ErrPublishNacked      = errors.New("message was nacked by the broker")
+	ErrPublishUnroutable  = errors.New("message could not be routed to any queue")
+	ErrPublishUnconfirmed = errors.New("broker did not confirm the message in time")
+)
+
+func PublishToRmq(instanceName string, message []byte, queueName string, exchange string) error {
 	instance, err := config.GetRMQInstance(instanceName)
 
 	if err != nil {
 		log.Errorf("Failed to get RabbitMQ instance: %v 💥", err)
-		return
+		return err
+	}
+
+	ch, err := instance.Borrow()
+
+	if err != nil {
+		log.Errorf("Failed to borrow RabbitMQ channel: %v 💥", err)
+		return err
 	}
 
-	ch := instance.Channel
+	defer instance.Return(ch)
 
 	q, err := ch.QueueDeclare(
 		queueName,
@@ -30,42 +50,108 @@ func PublishToRmq(instanceName string, message []byte, queueName string, exchang
 
 	if err != nil {
 		log.Errorf("Failed to declare queue: %v 💥", err)
-		return
+		return fmt.Errorf("failed to declare queue %s: %w", queueName, err)
+	}
+
+	if exchange != "" {
+		if err := ch.QueueBind(q.Name, q.Name, exchange, false, nil); err != nil {
+			log.Errorf("Failed to bind queue: %v 💥", err)
+			return fmt.Errorf("failed to bind queue %s to %s: %w", queueName, exchange, err)
+		}
 	}
 
-	err = ch.Publish(
+	drainReturns(ch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishConfirmTimeout)
+	defer cancel()
+
+	messageId := uuid.NewString()
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(
+		ctx,
 		exchange,
 		q.Name,
-		false,
+		true,
 		false,
 		amqp.Publishing{
-			Body: message,
+			MessageId:    messageId,
+			DeliveryMode: amqp.Persistent,
+			Body:         message,
 		},
 	)
 
 	if err != nil {
 		log.Errorf("Failed to publish message: %v 💥", err)
-		return
+		return fmt.Errorf("failed to publish message to %s: %w", queueName, err)
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+
+	if err != nil {
+		log.Errorf("Publish to %s was not confirmed: %v 💥", queueName, err)
+		ch.Close()
+		return fmt.Errorf("%w: %s", ErrPublishUnconfirmed, queueName)
+	}
+
+	if !acked {
+		log.Errorf("Publish to %s was nacked by broker 💥", queueName)
+		return fmt.Errorf("%w: %s", ErrPublishNacked, queueName)
+	}
+
+	if returned, ok := findReturn(ch, messageId); ok {
+		log.Errorf("Publish to %s was unroutable: %d %s 💥", queueName, returned.ReplyCode, returned.ReplyText)
+		return fmt.Errorf("%w: %s (%d %s)", ErrPublishUnroutable, queueName, returned.ReplyCode, returned.ReplyText)
 	}
 
 	log.Infof("Published message to queue %s ✅", queueName)
+
+	return nil
 }
 
-func PublishToMqtt(instance, topic, message string) {
+func drainReturns(ch *config.RMQChannel) {
+	for {
+		select {
+		case <-ch.Returns:
+		default:
+			return
+		}
+	}
+}
+
+func findReturn(ch *config.RMQChannel, messageId string) (amqp.Return, bool) {
+	for {
+		select {
+		case returned, ok := <-ch.Returns:
+			if !ok {
+				return amqp.Return{}, false
+			}
+
+			if returned.MessageId == messageId {
+				return returned, true
+			}
+		default:
+			return amqp.Return{}, false
+		}
+	}
+}
+
+func PublishToMqtt(instance, topic string, message []byte, qos byte, retained bool) error {
 	client, err := config.GetMqttInstance(instance)
 
 	if err != nil {
 		log.Errorf("Failed to get MQTT instance: %v 💥", err)
-		return
+		return err
 	}
 
-	token := client.Publish(topic, 0, false, message)
+	token := client.Publish(topic, qos, retained, message)
 	token.Wait()
 
 	if token.Error() != nil {
 		log.Errorf("Failed to publish message: %v 💥", token.Error())
-		return
+		return fmt.Errorf("failed to publish message to %s: %w", topic, token.Error())
 	}
 
 	log.Infof("Published message to topic %s ✅", topic)
+
+	return nil
 }
@@ -0,0 +1,18 @@
+package res
+
+import (
+	messagebroker "go/hioto/pkg/handler/message_broker"
+	"go/hioto/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ConsumerStatsHandler struct{}
+
+func NewConsumerStatsHandler() *ConsumerStatsHandler {
+	return &ConsumerStatsHandler{}
+}
+
+func (h *ConsumerStatsHandler) GetConsumerStatsHandler(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get consumer stats", messagebroker.GetDispatcherStats())
+}
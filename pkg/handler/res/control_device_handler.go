@@ -11,12 +11,14 @@ import (
 
 type ControlDeviceHandler struct {
 	controlDeviceService *service.ControlDeviceService
+	commandService       *service.CommandService
 	validator            *validator.Validate
 }
 
-func NewControlDeviceHandler(controlDeviceService *service.ControlDeviceService) *ControlDeviceHandler {
+func NewControlDeviceHandler(controlDeviceService *service.ControlDeviceService, commandService *service.CommandService) *ControlDeviceHandler {
 	return &ControlDeviceHandler{
 		controlDeviceService: controlDeviceService,
+		commandService:       commandService,
 		validator:            validator.New(),
 	}
 }
@@ -28,9 +30,19 @@ func (h *ControlDeviceHandler) ControlDeviceHandler(c *fiber.Ctx) error {
 		return err
 	}
 
-	if err := h.controlDeviceService.ControlDeviceLocal(&controlDto); err != nil {
+	response, err := h.controlDeviceService.ControlDeviceLocal(c.UserContext(), &controlDto, c.QueryBool("wait"))
+	if err != nil {
 		return err
 	}
 
-	return utils.SuccessResponse[any](c, fiber.StatusOK, "Success control device ✅", nil)
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success control device ✅", response)
+}
+
+func (h *ControlDeviceHandler) GetCommandHandler(c *fiber.Ctx) error {
+	response, err := h.commandService.GetCommand(c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get aktuator command", response)
 }
@@ -73,3 +73,18 @@ func (h *FloorHandler) DeleteFloor(c *fiber.Ctx) error {
 
 	return utils.SuccessResponse[any](c, fiber.StatusOK, "Success delete floor", nil)
 }
+
+func (h *FloorHandler) ControlFloor(c *fiber.Ctx) error {
+	var controlDto dto.ReqBulkControlDto
+
+	if err := utils.ValidateRequestBody(c, h.validator, &controlDto); err != nil {
+		return err
+	}
+
+	response, err := h.floorService.ControlFloor(c.UserContext(), c.Params("id"), &controlDto, c.QueryBool("wait"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success control floor ✅", response)
+}
@@ -0,0 +1,108 @@
+package res
+
+import (
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/service"
+	"go/hioto/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GroupHandler struct {
+	groupService *service.GroupService
+	validator    *validator.Validate
+}
+
+func NewGroupHandler(groupService *service.GroupService) *GroupHandler {
+	return &GroupHandler{groupService: groupService, validator: validator.New()}
+}
+
+func (h *GroupHandler) CreateGroup(c *fiber.Ctx) error {
+	var createDto dto.CreateGroupDto
+
+	if err := utils.ValidateRequestBody(c, h.validator, &createDto); err != nil {
+		return err
+	}
+
+	response, err := h.groupService.CreateGroup(&createDto)
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, "Success create group", response)
+}
+
+func (h *GroupHandler) GetAllGroups(c *fiber.Ctx) error {
+	response, err := h.groupService.GetAllGroups()
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get all groups", response)
+}
+
+func (h *GroupHandler) GetGroupByID(c *fiber.Ctx) error {
+	response, err := h.groupService.GetGroupByID(c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get group by id", response)
+}
+
+func (h *GroupHandler) UpdateGroup(c *fiber.Ctx) error {
+	var updateDto dto.UpdateGroupDto
+
+	if err := utils.ValidateRequestBody(c, h.validator, &updateDto); err != nil {
+		return err
+	}
+
+	response, err := h.groupService.UpdateGroup(c.Params("id"), &updateDto)
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success update group", response)
+}
+
+func (h *GroupHandler) DeleteGroup(c *fiber.Ctx) error {
+	if err := h.groupService.DeleteGroup(c.Params("id")); err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse[any](c, fiber.StatusOK, "Success delete group", nil)
+}
+
+func (h *GroupHandler) AddDevice(c *fiber.Ctx) error {
+	response, err := h.groupService.AddDevice(c.Params("id"), c.Params("guid"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success add device to group", response)
+}
+
+func (h *GroupHandler) RemoveDevice(c *fiber.Ctx) error {
+	response, err := h.groupService.RemoveDevice(c.Params("id"), c.Params("guid"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success remove device from group", response)
+}
+
+func (h *GroupHandler) ControlGroup(c *fiber.Ctx) error {
+	var controlDto dto.ReqBulkControlDto
+
+	if err := utils.ValidateRequestBody(c, h.validator, &controlDto); err != nil {
+		return err
+	}
+
+	response, err := h.groupService.ControlGroup(c.UserContext(), c.Params("id"), &controlDto, c.QueryBool("wait"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success control group ✅", response)
+}
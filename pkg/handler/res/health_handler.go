@@ -0,0 +1,25 @@
+package res
+
+import (
+	"go/hioto/pkg/service"
+	"go/hioto/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthHandler struct {
+	heartbeatService *service.HeartbeatService
+}
+
+func NewHealthHandler(heartbeatService *service.HeartbeatService) *HealthHandler {
+	return &HealthHandler{heartbeatService: heartbeatService}
+}
+
+func (h *HealthHandler) GetHealthHandler(c *fiber.Ctx) error {
+	response, err := h.heartbeatService.BuildHeartbeat()
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get gateway health", response)
+}
@@ -0,0 +1,34 @@
+package res
+
+import (
+	"go/hioto/pkg/service"
+	"go/hioto/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LogExportHandler struct {
+	logExportService *service.LogExportService
+}
+
+func NewLogExportHandler(logExportService *service.LogExportService) *LogExportHandler {
+	return &LogExportHandler{logExportService: logExportService}
+}
+
+func (h *LogExportHandler) GetExportStatusHandler(c *fiber.Ctx) error {
+	response, err := h.logExportService.GetStatus()
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get export status", response)
+}
+
+func (h *LogExportHandler) ExportNowHandler(c *fiber.Ctx) error {
+	response, err := h.logExportService.ExportNow(c.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Logs exported to cloud", response)
+}
@@ -0,0 +1,34 @@
+package res
+
+import (
+	"go/hioto/pkg/service"
+	"go/hioto/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OutboxHandler struct {
+	outboxService *service.OutboxService
+}
+
+func NewOutboxHandler(outboxService *service.OutboxService) *OutboxHandler {
+	return &OutboxHandler{outboxService: outboxService}
+}
+
+func (h *OutboxHandler) GetOutboxHandler(c *fiber.Ctx) error {
+	response, err := h.outboxService.GetOutbox(c.Query("status"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get outbox entries", response)
+}
+
+func (h *OutboxHandler) RetryOutboxHandler(c *fiber.Ctx) error {
+	response, err := h.outboxService.RetryOutbox(c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success retry outbox entry", response)
+}
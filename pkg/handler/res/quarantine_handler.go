@@ -0,0 +1,75 @@
+package res
+
+import (
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/service"
+	"go/hioto/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type QuarantineHandler struct {
+	quarantineService *service.QuarantineService
+	validator         *validator.Validate
+}
+
+func NewQuarantineHandler(quarantineService *service.QuarantineService) *QuarantineHandler {
+	return &QuarantineHandler{quarantineService: quarantineService, validator: validator.New()}
+}
+
+func (h *QuarantineHandler) GetAllQuarantineHandler(c *fiber.Ctx) error {
+	var params dto.GetQuarantinePagination
+
+	if err := c.QueryParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	meta, response, err := h.quarantineService.GetAllQuarantine(&params)
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponsePaginate(c, fiber.StatusOK, "Success get quarantined messages", response, meta)
+}
+
+func (h *QuarantineHandler) GetQuarantineByIDHandler(c *fiber.Ctx) error {
+	response, err := h.quarantineService.GetQuarantineByID(c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get quarantined message", response)
+}
+
+func (h *QuarantineHandler) UpdateQuarantineHandler(c *fiber.Ctx) error {
+	var updateDto dto.UpdateQuarantineDto
+
+	if err := utils.ValidateRequestBody(c, h.validator, &updateDto); err != nil {
+		return err
+	}
+
+	response, err := h.quarantineService.UpdateQuarantine(c.Params("id"), &updateDto)
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success update quarantined message", response)
+}
+
+func (h *QuarantineHandler) ReinjectQuarantineHandler(c *fiber.Ctx) error {
+	response, err := h.quarantineService.ReinjectQuarantine(c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success reinject quarantined message", response)
+}
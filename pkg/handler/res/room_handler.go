@@ -82,3 +82,18 @@ func (h *RoomHandler) DeleteRoom(c *fiber.Ctx) error {
 
 	return utils.SuccessResponse[any](c, fiber.StatusOK, "Success delete room", nil)
 }
+
+func (h *RoomHandler) ControlRoom(c *fiber.Ctx) error {
+	var controlDto dto.ReqBulkControlDto
+
+	if err := utils.ValidateRequestBody(c, h.validator, &controlDto); err != nil {
+		return err
+	}
+
+	response, err := h.roomService.ControlRoom(c.UserContext(), c.Params("id"), &controlDto, c.QueryBool("wait"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success control room ✅", response)
+}
@@ -0,0 +1,29 @@
+package res
+
+import (
+	"go/hioto/pkg/enum"
+	"go/hioto/pkg/schema"
+	"go/hioto/pkg/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SchemaHandler struct{}
+
+func NewSchemaHandler() *SchemaHandler {
+	return &SchemaHandler{}
+}
+
+func (h *SchemaHandler) GetSchemasHandler(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get payload schemas", schema.All())
+}
+
+func (h *SchemaHandler) GetSchemaHandler(c *fiber.Ctx) error {
+	response, ok := schema.Lookup(enum.EDeviceType(strings.ToUpper(c.Params("type"))))
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "Schema not found")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get payload schema", response)
+}
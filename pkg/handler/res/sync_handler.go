@@ -0,0 +1,59 @@
+package res
+
+import (
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/service"
+	"go/hioto/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SyncHandler struct {
+	syncService *service.SyncService
+	validator   *validator.Validate
+}
+
+func NewSyncHandler(syncService *service.SyncService) *SyncHandler {
+	return &SyncHandler{
+		syncService: syncService,
+		validator:   validator.New(),
+	}
+}
+
+func (h *SyncHandler) GetSyncStatusHandler(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get sync status", h.syncService.GetLastReport())
+}
+
+func (h *SyncHandler) GetSnapshotHandler(c *fiber.Ctx) error {
+	response, err := h.syncService.BuildSnapshot()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Error building snapshot")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get snapshot", response)
+}
+
+func (h *SyncHandler) PublishSnapshotHandler(c *fiber.Ctx) error {
+	response, err := h.syncService.PublishSnapshot()
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusAccepted, "Snapshot queued for cloud", response)
+}
+
+func (h *SyncHandler) ReconcileHandler(c *fiber.Ctx) error {
+	var syncDto dto.ReqSyncDto
+
+	if err := utils.ValidateRequestBody(c, h.validator, &syncDto); err != nil {
+		return err
+	}
+
+	response, err := h.syncService.Reconcile(&syncDto)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success reconcile with cloud snapshot", response)
+}
@@ -0,0 +1,34 @@
+package res
+
+import (
+	"go/hioto/pkg/service"
+	"go/hioto/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TelemetryHandler struct {
+	telemetryService *service.TelemetryService
+}
+
+func NewTelemetryHandler(telemetryService *service.TelemetryService) *TelemetryHandler {
+	return &TelemetryHandler{telemetryService: telemetryService}
+}
+
+func (h *TelemetryHandler) GetTelemetryStatsHandler(c *fiber.Ctx) error {
+	response, err := h.telemetryService.GetStats(c.Params("guid"), c.Query("metric"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get telemetry stats", response)
+}
+
+func (h *TelemetryHandler) GetTelemetrySeriesHandler(c *fiber.Ctx) error {
+	response, err := h.telemetryService.GetSeries(c.Params("guid"), c.Query("metric"), c.Query("from"), c.Query("to"), c.Query("bucket"))
+	if err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success get telemetry series", response)
+}
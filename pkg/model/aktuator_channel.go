@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type AktuatorChannel struct {
+	ID         uint      `gorm:"autoIncrement" json:"id"`
+	DeviceGuid string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_aktuator_channel" json:"guid"`
+	Channel    int       `gorm:"not null;uniqueIndex:idx_aktuator_channel" json:"channel"`
+	Status     string    `gorm:"type:varchar(8);not null" json:"status"`
+	UpdatedAt  time.Time `gorm:"not null" json:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
+
+type AktuatorCommand struct {
+	ID            uint                `gorm:"autoIncrement" json:"id"`
+	CorrelationID string              `gorm:"type:varchar(64);not null;uniqueIndex" json:"correlation_id"`
+	Guid          string              `gorm:"type:varchar(255);not null;index" json:"guid"`
+	Channel       int                 `gorm:"not null;default:0" json:"channel"`
+	Value         string              `gorm:"type:varchar(8);not null" json:"value"`
+	Source        enum.ECommandSource `gorm:"type:varchar(16);not null" json:"source"`
+	Status        enum.ECommandStatus `gorm:"type:varchar(16);not null;index" json:"status"`
+	Error         string              `gorm:"type:text" json:"error"`
+	ExpiresAt     time.Time           `gorm:"not null;index" json:"expires_at"`
+	ConfirmedAt   *time.Time          `gorm:"default:null" json:"confirmed_at"`
+	CreatedAt     time.Time           `gorm:"not null" json:"created_at"`
+	UpdatedAt     time.Time           `gorm:"not null" json:"updated_at"`
+}
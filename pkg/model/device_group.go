@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type DeviceGroup struct {
+	ID        uint           `gorm:"autoIncrement;primaryKey" json:"id"`
+	Tag       string         `gorm:"type:varchar(64);not null;uniqueIndex" json:"tag"`
+	Name      string         `gorm:"type:varchar(255);not null" json:"name"`
+	Devices   []Registration `gorm:"many2many:device_group_members;joinForeignKey:GroupID;references:Guid;joinReferences:DeviceGuid" json:"devices"`
+	CreatedAt time.Time      `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"not null" json:"updated_at"`
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type ExportCursor struct {
+	Stream       string     `gorm:"type:varchar(64);primaryKey" json:"stream"`
+	LastID       uint       `gorm:"not null;default:0" json:"last_id"`
+	LastExportAt *time.Time `gorm:"" json:"last_export_at"`
+	LastError    string     `gorm:"type:text" json:"last_error"`
+	UpdatedAt    time.Time  `gorm:"not null" json:"updated_at"`
+}
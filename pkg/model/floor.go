@@ -6,6 +6,7 @@ import (
 
 type Floor struct {
 	ID        uint      `gorm:"autoIncrement;primaryKey" json:"id"`
+	Guid      string    `gorm:"type:varchar(255);uniqueIndex" json:"guid"`
 	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
 	Rooms     []Room    `gorm:"foreignKey:FloorID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"rooms"`
 	CreatedAt time.Time `gorm:"not null" json:"created_at"`
@@ -10,7 +10,9 @@ type MonitoringHistory struct {
 	DeviceGuid string           `gorm:"type:varchar(255);not null" json:"device_guid"`
 	DeviceName string           `gorm:"type:varchar(255);not null" json:"device_name"`
 	DeviceType enum.EDeviceType `gorm:"type:varchar(255);not null" json:"device_type"`
-	Value      string           `gorm:"type:varchar(8);not null" json:"value"`
+	Name       string           `gorm:"type:varchar(64);not null;default:''" json:"name"`
+	Value      string           `gorm:"type:varchar(255);not null" json:"value"`
+	Unit       string           `gorm:"type:varchar(16);not null;default:''" json:"unit"`
 	Device     Registration     `gorm:"foreignKey:DeviceGuid;references:Guid" json:"device"`
 	Time       time.Time        `gorm:"not null" json:"time"`
 }
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
+
+type Outbox struct {
+	ID            uint               `gorm:"autoIncrement" json:"id"`
+	InstanceName  string             `gorm:"type:varchar(255);not null" json:"instance_name"`
+	Exchange      string             `gorm:"type:varchar(255);not null" json:"exchange"`
+	QueueName     string             `gorm:"type:varchar(255);not null" json:"queue_name"`
+	Payload       string             `gorm:"type:text;not null" json:"payload"`
+	Status        enum.EOutboxStatus `gorm:"type:varchar(16);not null;index" json:"status"`
+	Attempts      int                `gorm:"type:int;not null;default:0" json:"attempts"`
+	LastError     string             `gorm:"type:text" json:"last_error"`
+	NextAttemptAt time.Time          `gorm:"not null" json:"next_attempt_at"`
+	CreatedAt     time.Time          `gorm:"not null" json:"created_at"`
+	UpdatedAt     time.Time          `gorm:"not null" json:"updated_at"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
+
+type ProcessedMessage struct {
+	MessageID   string            `gorm:"type:varchar(255);primaryKey" json:"message_id"`
+	Type        enum.EMessageType `gorm:"type:varchar(64);not null" json:"type"`
+	IssuedAt    *time.Time        `gorm:"" json:"issued_at"`
+	ProcessedAt time.Time         `gorm:"not null;index" json:"processed_at"`
+}
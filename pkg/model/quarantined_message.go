@@ -0,0 +1,19 @@
+package model
+
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
+
+type QuarantinedMessage struct {
+	ID          uint                   `gorm:"autoIncrement" json:"id"`
+	Broker      string                 `gorm:"type:varchar(255);not null" json:"broker"`
+	Route       string                 `gorm:"type:varchar(255);not null" json:"route"`
+	Destination string                 `gorm:"type:varchar(255);not null;index" json:"destination"`
+	Payload     []byte                 `gorm:"type:blob;not null" json:"payload"`
+	Error       string                 `gorm:"type:text;not null" json:"error"`
+	Status      enum.EQuarantineStatus `gorm:"type:varchar(16);not null;index" json:"status"`
+	Attempts    int                    `gorm:"type:int;not null;default:0" json:"attempts"`
+	CreatedAt   time.Time              `gorm:"not null;index" json:"created_at"`
+	UpdatedAt   time.Time              `gorm:"not null" json:"updated_at"`
+}
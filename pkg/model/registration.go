@@ -16,6 +16,8 @@ type Registration struct {
 	Minor             string              `gorm:"type:varchar(255);not null" json:"minor"`
 	Status            string              `gorm:"type:varchar(255);" json:"status"`
 	StatusDevice      enum.EDeviceStatus  `gorm:"type:varchar(255);default:'1" json:"status_device"`
+	TopicScheme       enum.ETopicScheme   `gorm:"type:varchar(16);not null;default:'legacy'" json:"topic_scheme"`
+	PayloadCodec      enum.EPayloadCodec  `gorm:"type:varchar(16);not null;default:'text'" json:"payload_codec"`
 	LastSeen          time.Time           `gorm:"" json:"last_seen"`
 	CreatedAt         time.Time           `gorm:"not null" json:"created_at"`
 	UpdatedAt         time.Time           `gorm:"not null" json:"updated_at"`
@@ -27,4 +29,6 @@ type Registration struct {
 	RoomID            *uint               `gorm:"default:null" json:"room_id"`
 	Room              *Room               `gorm:"foreignKey:RoomID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"room"`
 	MonitoringHistory []MonitoringHistory `gorm:"foreignKey:DeviceGuid;references:Guid" json:"monitoring_history"`
+	Channels          []AktuatorChannel   `gorm:"foreignKey:DeviceGuid;references:Guid" json:"channels"`
+	Groups            []DeviceGroup       `gorm:"many2many:device_group_members;foreignKey:Guid;joinForeignKey:DeviceGuid;joinReferences:GroupID" json:"groups"`
 }
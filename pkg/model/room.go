@@ -4,6 +4,7 @@ import "time"
 
 type Room struct {
 	ID        uint      `gorm:"autoIncrement;primaryKey" json:"id"`
+	Guid      string    `gorm:"type:varchar(255);uniqueIndex" json:"guid"`
 	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
 	FloorID   uint      `gorm:"not null" json:"floor_id"`
 	Floor     Floor     `gorm:"foreignKey:FloorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"floor"`
@@ -0,0 +1,8 @@
+package model
+
+import "time"
+
+type SchemaMigration struct {
+	Name      string    `gorm:"type:varchar(128);primaryKey" json:"name"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
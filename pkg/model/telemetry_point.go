@@ -0,0 +1,17 @@
+package model
+
+import (
+	"go/hioto/pkg/enum"
+	"time"
+)
+
+type TelemetryPoint struct {
+	ID         uint                   `gorm:"autoIncrement" json:"id"`
+	DeviceGuid string                 `gorm:"type:varchar(255);not null;index:idx_telemetry_device_metric_time,priority:1" json:"device_guid"`
+	Metric     string                 `gorm:"type:varchar(64);not null;index:idx_telemetry_device_metric_time,priority:2" json:"metric"`
+	Value      float64                `gorm:"not null" json:"value"`
+	Unit       string                 `gorm:"type:varchar(16);not null;default:''" json:"unit"`
+	Quality    enum.ETelemetryQuality `gorm:"type:varchar(16);not null;default:'good'" json:"quality"`
+	HistoryID  *uint                  `gorm:"uniqueIndex" json:"history_id"`
+	Time       time.Time              `gorm:"not null;index;index:idx_telemetry_device_metric_time,priority:3" json:"time"`
+}
@@ -4,101 +4,269 @@ import (
 	"context"
 	"fmt"
 	"go/hioto/config"
+	"go/hioto/pkg/codec"
+	"go/hioto/pkg/enum"
 	"go/hioto/pkg/handler/consumer"
 	messagebroker "go/hioto/pkg/handler/message_broker"
+	"go/hioto/pkg/service"
+	"strings"
 
 	"github.com/gofiber/fiber/v2/log"
 )
 
 type ConsumerMqtt struct {
-	InstanceName string
-	Topic        string
-	HandlerFunc  func([]byte)
+	Broker      messagebroker.Broker
+	Topic       string
+	HandlerFunc messagebroker.MessageHandler
+	PerDevice   bool
+}
+
+type ConsumerRmq struct {
+	Broker      messagebroker.Broker
+	Queue       string
+	HandlerFunc messagebroker.MessageHandler
 }
 
 type ConsumerMessageBroker struct {
-	ctx             context.Context
-	consumerHandler *consumer.ConsumerHandler
+	ctx                  context.Context
+	consumerHandler      *consumer.ConsumerHandler
+	quarantineService    *service.QuarantineService
+	homeAssistantService *service.HomeAssistantService
+	cloudMqtt            messagebroker.Broker
+	localMqtt            messagebroker.Broker
+	localRmq             messagebroker.Broker
 }
 
 func NewConsumerMessageBroker(
 	ctx context.Context,
 	consumerHandler *consumer.ConsumerHandler,
+	quarantineService *service.QuarantineService,
+	homeAssistantService *service.HomeAssistantService,
+	cloudMqtt messagebroker.Broker,
+	localMqtt messagebroker.Broker,
+	localRmq messagebroker.Broker,
 ) *ConsumerMessageBroker {
 	return &ConsumerMessageBroker{
-		ctx:             ctx,
-		consumerHandler: consumerHandler,
+		ctx:                  ctx,
+		consumerHandler:      consumerHandler,
+		quarantineService:    quarantineService,
+		homeAssistantService: homeAssistantService,
+		cloudMqtt:            cloudMqtt,
+		localMqtt:            localMqtt,
+		localRmq:             localRmq,
 	}
 }
 
 func (c *ConsumerMessageBroker) StartConsumer() {
 	routes := []ConsumerMqtt{
 		{
-			InstanceName: config.MQTT_CLOUD_INSTANCE_NAME.GetValue(),
+			Broker: c.cloudMqtt,
 			Topic: fmt.Sprintf(
 				"%s/%s",
 				config.CONTROL_ROUTING_KEY.GetValue(),
 				config.MAC_ADDRESS.GetValue(),
 			),
-			HandlerFunc: c.consumerHandler.ControlHandler,
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_CONTROL, c.consumerHandler.ControlHandler),
 		},
 		{
-			InstanceName: config.MQTT_CLOUD_INSTANCE_NAME.GetValue(),
+			Broker: c.cloudMqtt,
 			Topic: fmt.Sprintf(
 				"%s/%s",
 				config.REGISTRATION_ROUTING_KEY.GetValue(),
 				config.MAC_ADDRESS.GetValue(),
 			),
-			HandlerFunc: c.consumerHandler.RegistrationFromCloudHandler,
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_REGISTER, c.consumerHandler.RegistrationFromCloudHandler),
 		},
 		{
-			InstanceName: config.MQTT_CLOUD_INSTANCE_NAME.GetValue(),
+			Broker: c.cloudMqtt,
 			Topic: fmt.Sprintf(
 				"%s/%s",
 				config.UPDATE_DEVICE_ROUTING_KEY.GetValue(),
 				config.MAC_ADDRESS.GetValue(),
 			),
-			HandlerFunc: c.consumerHandler.UpdateDeviceFromCloudHandler,
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_UPDATE_DEVICE, c.consumerHandler.UpdateDeviceFromCloudHandler),
 		},
 		{
-			InstanceName: config.MQTT_CLOUD_INSTANCE_NAME.GetValue(),
+			Broker: c.cloudMqtt,
 			Topic: fmt.Sprintf(
 				"%s/%s",
 				config.DELETE_DEVICE_ROUTING_KEY.GetValue(),
 				config.MAC_ADDRESS.GetValue(),
 			),
-			HandlerFunc: c.consumerHandler.DeleteDeviceFromCloudHandler,
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_DELETE_DEVICE, c.consumerHandler.DeleteDeviceFromCloudHandler),
+		},
+		{
+			Broker: c.cloudMqtt,
+			Topic: fmt.Sprintf(
+				"%s/%s",
+				config.SYNC_ROUTING_KEY.GetValue(),
+				config.MAC_ADDRESS.GetValue(),
+			),
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_SYNC, c.consumerHandler.SyncHandler),
+		},
+		{
+			Broker: c.cloudMqtt,
+			Topic: fmt.Sprintf(
+				"%s/%s",
+				config.RULES_REQUEST_ROUTING_KEY.GetValue(),
+				config.MAC_ADDRESS.GetValue(),
+			),
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_RULES, c.consumerHandler.RulesFromCloudHandler),
+		},
+		{
+			Broker: c.cloudMqtt,
+			Topic: fmt.Sprintf(
+				"%s/%s",
+				config.RULES_DELETE_ROUTING_KEY.GetValue(),
+				config.MAC_ADDRESS.GetValue(),
+			),
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_RULES_DELETE, c.consumerHandler.DeleteRulesFromCloudHandler),
+		},
+		{
+			Broker: c.cloudMqtt,
+			Topic: fmt.Sprintf(
+				"%s/%s",
+				config.FLOOR_ROUTING_KEY.GetValue(),
+				config.MAC_ADDRESS.GetValue(),
+			),
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_FLOOR, c.consumerHandler.FloorFromCloudHandler),
+		},
+		{
+			Broker: c.cloudMqtt,
+			Topic: fmt.Sprintf(
+				"%s/%s",
+				config.ROOM_ROUTING_KEY.GetValue(),
+				config.MAC_ADDRESS.GetValue(),
+			),
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_ROOM, c.consumerHandler.RoomFromCloudHandler),
+		},
+		{
+			Broker: c.cloudMqtt,
+			Topic: fmt.Sprintf(
+				"%s/%s",
+				config.FLOOR_CONTROL_ROUTING_KEY.GetValue(),
+				config.MAC_ADDRESS.GetValue(),
+			),
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_FLOOR_CONTROL, c.consumerHandler.FloorControlFromCloudHandler),
+		},
+		{
+			Broker: c.cloudMqtt,
+			Topic: fmt.Sprintf(
+				"%s/%s",
+				config.ROOM_CONTROL_ROUTING_KEY.GetValue(),
+				config.MAC_ADDRESS.GetValue(),
+			),
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_ROOM_CONTROL, c.consumerHandler.RoomControlFromCloudHandler),
 		},
 		{
-			InstanceName: config.MQTT_LOCAL_INSTANCE_NAME.GetValue(),
-			Topic:        config.AKTUATOR_TOPIC.GetValue(),
-			HandlerFunc:  c.consumerHandler.TestingConsumeAktuator,
+			Broker:      c.localMqtt,
+			Topic:       config.AKTUATOR_TOPIC.GetValue(),
+			HandlerFunc: c.consumerHandler.AktuatorHandler,
 		},
 		{
-			InstanceName: config.MQTT_LOCAL_INSTANCE_NAME.GetValue(),
-			Topic:        config.SENSOR_TOPIC.GetValue(),
-			HandlerFunc:  c.consumerHandler.ControlSensorHandler,
+			Broker:      c.localMqtt,
+			Topic:       config.SENSOR_TOPIC.GetValue(),
+			HandlerFunc: c.consumerHandler.ControlSensorHandler(topicCodec(config.SENSOR_TOPIC.GetValue())),
 		},
 		{
-			InstanceName: config.MQTT_LOCAL_INSTANCE_NAME.GetValue(),
-			Topic:        config.SENSOR_GAS_DETECTOR_TOPIC.GetValue(),
-			HandlerFunc:  c.consumerHandler.ControlGasDetectorHandler,
+			Broker:      c.localMqtt,
+			Topic:       config.SENSOR_GAS_DETECTOR_TOPIC.GetValue(),
+			HandlerFunc: c.consumerHandler.ControlGasDetectorHandler,
 		},
 		{
-			InstanceName: config.MQTT_LOCAL_INSTANCE_NAME.GetValue(),
-			Topic:        config.MONITORING_TOPIC.GetValue(),
-			HandlerFunc:  c.consumerHandler.MonitoringDataDevice,
+			Broker:      c.localMqtt,
+			Topic:       config.MONITORING_TOPIC.GetValue(),
+			HandlerFunc: c.consumerHandler.MonitoringDataDevice(topicCodec(config.MONITORING_TOPIC.GetValue())),
+		},
+		{
+			Broker:      c.localMqtt,
+			Topic:       messagebroker.DeviceTopicFilter(messagebroker.DEVICE_TOPIC_STATE),
+			HandlerFunc: c.consumerHandler.ControlSensorHandler(topicCodec(messagebroker.DeviceTopicFilter(messagebroker.DEVICE_TOPIC_STATE))),
+			PerDevice:   true,
+		},
+		{
+			Broker:      c.localMqtt,
+			Topic:       messagebroker.DeviceTopicFilter(messagebroker.DEVICE_TOPIC_TELEMETRY),
+			HandlerFunc: c.consumerHandler.MonitoringDataDevice(topicCodec(messagebroker.DeviceTopicFilter(messagebroker.DEVICE_TOPIC_TELEMETRY))),
+			PerDevice:   true,
+		},
+		{
+			Broker:      c.localMqtt,
+			Topic:       messagebroker.DeviceTopicFilter(messagebroker.DEVICE_TOPIC_ACK),
+			HandlerFunc: c.consumerHandler.AktuatorHandler,
+			PerDevice:   true,
 		},
 	}
 
 	for _, route := range routes {
-		go messagebroker.ConsumeMQTTTopic(
-			c.ctx,
-			route.InstanceName,
-			route.Topic,
-			route.HandlerFunc,
-		)
+		if route.Broker == c.cloudMqtt && !config.IsCloudMqttConfigured() {
+			log.Warnf("⚠️ Skipping cloud consumer %s, MQTT cloud is not configured", route.Topic)
+			continue
+		}
+
+		if route.PerDevice {
+			c.subscribeDelivery(route.Broker, route.Topic, messagebroker.DeviceTopicHandler(route.HandlerFunc))
+			continue
+		}
+
+		c.subscribe(route.Broker, route.Topic, route.HandlerFunc)
+	}
+
+	if c.homeAssistantService.Enabled() {
+		c.subscribeDelivery(c.localMqtt, c.homeAssistantService.CommandTopicFilter(), c.homeAssistantService.CommandHandler)
+		c.subscribe(c.localMqtt, c.homeAssistantService.StatusTopic(), c.homeAssistantService.StatusHandler)
 	}
 
 	log.Info("✅ MQTT consumers started successfully")
+
+	rmqRoutes := []ConsumerRmq{
+		{
+			Broker:      c.localRmq,
+			Queue:       config.RULES_ROUTING_KEY.GetValue(),
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_RULES, c.consumerHandler.RulesHandler),
+		},
+		{
+			Broker:      c.localRmq,
+			Queue:       config.REGISTRATION_LOCAL_ROUTING_KEY.GetValue(),
+			HandlerFunc: c.consumerHandler.Envelope(enum.MESSAGE_REGISTER, c.consumerHandler.RegistrationHandler),
+		},
+	}
+
+	if !config.IsLocalRmqConfigured() {
+		log.Warn("⚠️ Skipping RabbitMQ local consumers, RabbitMQ local is not configured")
+		return
+	}
+
+	for _, route := range rmqRoutes {
+		c.subscribe(route.Broker, route.Queue, route.HandlerFunc)
+	}
+
+	log.Info("✅ RabbitMQ consumers started successfully")
+}
+
+func (c *ConsumerMessageBroker) subscribe(broker messagebroker.Broker, destination string, handler messagebroker.MessageHandler) {
+	c.subscribeDelivery(broker, destination, messagebroker.BodyHandler(handler))
+}
+
+func (c *ConsumerMessageBroker) subscribeDelivery(broker messagebroker.Broker, destination string, handler messagebroker.DeliveryHandler) {
+	if err := broker.Subscribe(
+		c.ctx,
+		destination,
+		messagebroker.AckAfter(
+			broker,
+			c.quarantineService.Guard(broker.Name(), destination, handler),
+		),
+	); err != nil {
+		log.Errorf("❌ Failed to start consumer %s on %s: %v", destination, broker.Name(), err)
+	}
+}
+
+func topicCodec(topic string) string {
+	for _, filter := range strings.Split(config.MQTT_CBOR_TOPICS.GetValue(), ",") {
+		if filter = strings.TrimSpace(filter); filter != "" && messagebroker.MatchTopic(filter, topic) {
+			return codec.CODEC_CBOR
+		}
+	}
+
+	return codec.CODEC_AUTO
 }
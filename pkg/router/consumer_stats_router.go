@@ -0,0 +1,13 @@
+package router
+
+import (
+	"go/hioto/pkg/handler/res"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ConsumerStatsRouter(router fiber.Router) {
+	consumerStatsHandler := res.NewConsumerStatsHandler()
+
+	router.Get("/consumers/stats", consumerStatsHandler.GetConsumerStatsHandler)
+}
@@ -8,8 +8,9 @@ import (
 	"gorm.io/gorm"
 )
 
-func ControlDeviceRouter(router fiber.Router, db *gorm.DB, controlDeviceService *service.ControlDeviceService) {
-	controlDeviceHandler := res.NewControlDeviceHandler(controlDeviceService)
+func ControlDeviceRouter(router fiber.Router, db *gorm.DB, controlDeviceService *service.ControlDeviceService, commandService *service.CommandService) {
+	controlDeviceHandler := res.NewControlDeviceHandler(controlDeviceService, commandService)
 
 	router.Put("/device/control", controlDeviceHandler.ControlDeviceHandler)
+	router.Get("/device/command/:id", controlDeviceHandler.GetCommandHandler)
 }
@@ -16,4 +16,5 @@ func FloorRouter(router fiber.Router, db *gorm.DB, floorService *service.FloorSe
 	router.Get("/floor/:id", floorHandler.GetFloorByID)
 	router.Put("/floor/:id", floorHandler.UpdateFloor)
 	router.Delete("/floor/:id", floorHandler.DeleteFloor)
+	router.Put("/floor/:id/control", floorHandler.ControlFloor)
 }
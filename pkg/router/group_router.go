@@ -0,0 +1,22 @@
+package router
+
+import (
+	"go/hioto/pkg/handler/res"
+	"go/hioto/pkg/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func GroupRouter(router fiber.Router, db *gorm.DB, groupService *service.GroupService) {
+	groupHandler := res.NewGroupHandler(groupService)
+
+	router.Post("/group", groupHandler.CreateGroup)
+	router.Get("/groups", groupHandler.GetAllGroups)
+	router.Get("/group/:id", groupHandler.GetGroupByID)
+	router.Put("/group/:id", groupHandler.UpdateGroup)
+	router.Delete("/group/:id", groupHandler.DeleteGroup)
+	router.Put("/group/:id/control", groupHandler.ControlGroup)
+	router.Post("/group/:id/device/:guid", groupHandler.AddDevice)
+	router.Delete("/group/:id/device/:guid", groupHandler.RemoveDevice)
+}
@@ -0,0 +1,15 @@
+package router
+
+import (
+	"go/hioto/pkg/handler/res"
+	"go/hioto/pkg/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func HealthRouter(router fiber.Router, db *gorm.DB, heartbeatService *service.HeartbeatService) {
+	healthHandler := res.NewHealthHandler(heartbeatService)
+
+	router.Get("/health", healthHandler.GetHealthHandler)
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"go/hioto/pkg/handler/res"
+	"go/hioto/pkg/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func LogExportRouter(router fiber.Router, db *gorm.DB, logExportService *service.LogExportService) {
+	logExportHandler := res.NewLogExportHandler(logExportService)
+
+	router.Get("/export", logExportHandler.GetExportStatusHandler)
+	router.Post("/export", logExportHandler.ExportNowHandler)
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"go/hioto/pkg/handler/res"
+	"go/hioto/pkg/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func OutboxRouter(router fiber.Router, db *gorm.DB, outboxService *service.OutboxService) {
+	outboxHandler := res.NewOutboxHandler(outboxService)
+
+	router.Get("/outbox", outboxHandler.GetOutboxHandler)
+	router.Put("/outbox/:id/retry", outboxHandler.RetryOutboxHandler)
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"go/hioto/pkg/handler/res"
+	"go/hioto/pkg/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func QuarantineRouter(router fiber.Router, db *gorm.DB, quarantineService *service.QuarantineService) {
+	quarantineHandler := res.NewQuarantineHandler(quarantineService)
+
+	router.Get("/quarantine", quarantineHandler.GetAllQuarantineHandler)
+	router.Get("/quarantine/:id", quarantineHandler.GetQuarantineByIDHandler)
+	router.Put("/quarantine/:id", quarantineHandler.UpdateQuarantineHandler)
+	router.Post("/quarantine/:id/reinject", quarantineHandler.ReinjectQuarantineHandler)
+}
@@ -17,4 +17,5 @@ func RoomRouter(router fiber.Router, db *gorm.DB, roomService *service.RoomServi
 	router.Get("/room/:id", roomHandler.GetRoomByID)
 	router.Put("/room/:id", roomHandler.UpdateRoom)
 	router.Delete("/room/:id", roomHandler.DeleteRoom)
+	router.Put("/room/:id/control", roomHandler.ControlRoom)
 }
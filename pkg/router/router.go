@@ -11,14 +11,31 @@ func Router(
 	router fiber.Router,
 	db *gorm.DB,
 	controlDeviceService *service.ControlDeviceService,
+	commandService *service.CommandService,
 	deviceService *service.DeviceService,
 	rulesService *service.RuleService,
 	floorService *service.FloorService,
 	roomService *service.RoomService,
+	outboxService *service.OutboxService,
+	quarantineService *service.QuarantineService,
+	syncService *service.SyncService,
+	logExportService *service.LogExportService,
+	heartbeatService *service.HeartbeatService,
+	telemetryService *service.TelemetryService,
+	groupService *service.GroupService,
 ) {
-	ControlDeviceRouter(router, db, controlDeviceService)
+	ControlDeviceRouter(router, db, controlDeviceService, commandService)
 	DeviceRouter(router, db, deviceService)
 	RulesRouter(router, db, rulesService)
 	FloorRouter(router, db, floorService)
 	RoomRouter(router, db, roomService)
+	OutboxRouter(router, db, outboxService)
+	QuarantineRouter(router, db, quarantineService)
+	ConsumerStatsRouter(router)
+	SchemaRouter(router)
+	SyncRouter(router, db, syncService)
+	LogExportRouter(router, db, logExportService)
+	HealthRouter(router, db, heartbeatService)
+	TelemetryRouter(router, db, telemetryService)
+	GroupRouter(router, db, groupService)
 }
@@ -0,0 +1,14 @@
+package router
+
+import (
+	"go/hioto/pkg/handler/res"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SchemaRouter(router fiber.Router) {
+	schemaHandler := res.NewSchemaHandler()
+
+	router.Get("/schemas", schemaHandler.GetSchemasHandler)
+	router.Get("/schemas/:type", schemaHandler.GetSchemaHandler)
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"go/hioto/pkg/handler/res"
+	"go/hioto/pkg/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func SyncRouter(router fiber.Router, db *gorm.DB, syncService *service.SyncService) {
+	syncHandler := res.NewSyncHandler(syncService)
+
+	router.Get("/sync", syncHandler.GetSyncStatusHandler)
+	router.Get("/sync/snapshot", syncHandler.GetSnapshotHandler)
+	router.Post("/sync/snapshot", syncHandler.PublishSnapshotHandler)
+	router.Post("/sync/reconcile", syncHandler.ReconcileHandler)
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"go/hioto/pkg/handler/res"
+	"go/hioto/pkg/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func TelemetryRouter(router fiber.Router, db *gorm.DB, telemetryService *service.TelemetryService) {
+	telemetryHandler := res.NewTelemetryHandler(telemetryService)
+
+	router.Get("/telemetry/:guid/stats", telemetryHandler.GetTelemetryStatsHandler)
+	router.Get("/telemetry/:guid/series", telemetryHandler.GetTelemetrySeriesHandler)
+}
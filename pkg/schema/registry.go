@@ -0,0 +1,91 @@
+package schema
+
+import (
+	"go/hioto/pkg/codec"
+	"go/hioto/pkg/enum"
+)
+
+func bound(value float64) *float64 {
+	return &value
+}
+
+var celsiusAliases = map[string]string{"c": "°C", "°c": "°C", "celsius": "°C"}
+
+var registry = map[enum.EDeviceType]*Schema{
+	enum.AKTUATOR: {
+		Type:   enum.AKTUATOR,
+		Fields: []Field{{Name: "state", Kind: KIND_BINARY}},
+	},
+	enum.SENSOR: {
+		Type:   enum.SENSOR,
+		Fields: []Field{{Name: "pattern", Kind: KIND_BITS}},
+	},
+	enum.SENSOR_PARKING: {
+		Type:   enum.SENSOR_PARKING,
+		Fields: []Field{{Name: "occupied", Kind: KIND_BINARY}},
+	},
+	enum.SENSOR_TEMPERATURE: {
+		Type: enum.SENSOR_TEMPERATURE,
+		Fields: []Field{
+			{Name: "temperature", Kind: KIND_NUMBER, Unit: "°C", Aliases: celsiusAliases, Min: bound(-40), Max: bound(125)},
+			{Name: "humidity", Kind: KIND_NUMBER, Unit: "%", Min: bound(0), Max: bound(100)},
+		},
+	},
+	enum.SENSOR_WATER_TANK: {
+		Type: enum.SENSOR_WATER_TANK,
+		Fields: []Field{
+			{Name: "level", Kind: KIND_ENUM, Values: []string{"HIGH", "MEDIUM", "LOW"}},
+			{Name: "percentage", Kind: KIND_NUMBER, Unit: "%", Min: bound(0), Max: bound(100)},
+		},
+	},
+	enum.SENSOR_CAMERA: {
+		Type:   enum.SENSOR_CAMERA,
+		Fields: []Field{{Name: "image", Kind: KIND_IMAGE}},
+	},
+	enum.SENSOR_GAS_DETECTOR: {
+		Type:   enum.SENSOR_GAS_DETECTOR,
+		Fields: []Field{{Name: "gas", Kind: KIND_NUMBER, Unit: "ppm", Min: bound(0)}},
+	},
+	enum.AI: {
+		Type:   enum.AI,
+		Fields: []Field{{Name: "result", Kind: KIND_TEXT}},
+		Open:   true,
+	},
+}
+
+func Lookup(deviceType enum.EDeviceType) (*Schema, bool) {
+	schema, ok := registry[deviceType]
+
+	return schema, ok
+}
+
+func All() []*Schema {
+	types := []enum.EDeviceType{
+		enum.AI,
+		enum.SENSOR,
+		enum.SENSOR_TEMPERATURE,
+		enum.SENSOR_WATER_TANK,
+		enum.SENSOR_CAMERA,
+		enum.SENSOR_PARKING,
+		enum.SENSOR_GAS_DETECTOR,
+		enum.AKTUATOR,
+	}
+
+	schemas := make([]*Schema, 0, len(types))
+
+	for _, deviceType := range types {
+		schemas = append(schemas, registry[deviceType])
+	}
+
+	return schemas
+}
+
+func Parse(deviceType enum.EDeviceType, readings []codec.Reading) ([]Reading, error) {
+	schema, ok := Lookup(deviceType)
+
+	if !ok {
+		schema = &Schema{Type: deviceType, Fields: []Field{{Name: "value", Kind: KIND_TEXT}}, Open: true}
+	}
+
+	return schema.Parse(readings)
+}
@@ -0,0 +1,225 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"go/hioto/pkg/codec"
+	"go/hioto/pkg/enum"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidReading = errors.New("invalid reading")
+
+type Kind string
+
+const (
+	KIND_BINARY Kind = "binary"
+	KIND_BITS   Kind = "bits"
+	KIND_NUMBER Kind = "number"
+	KIND_ENUM   Kind = "enum"
+	KIND_IMAGE  Kind = "image"
+	KIND_TEXT   Kind = "text"
+)
+
+const maxTextLength = 255
+
+var (
+	numberPattern = regexp.MustCompile(`^\s*([-+]?\d+(?:[.,]\d+)?)\s*(.*?)\s*$`)
+	bitsPattern   = regexp.MustCompile(`^[01]{1,8}$`)
+	imagePattern  = regexp.MustCompile(`(?i)^[\w\-./:]+\.(jpe?g|png)$`)
+)
+
+type Field struct {
+	Name    string            `json:"name"`
+	Kind    Kind              `json:"kind"`
+	Unit    string            `json:"unit,omitempty"`
+	Aliases map[string]string `json:"-"`
+	Min     *float64          `json:"min,omitempty"`
+	Max     *float64          `json:"max,omitempty"`
+	Values  []string          `json:"values,omitempty"`
+}
+
+type Schema struct {
+	Type   enum.EDeviceType `json:"type"`
+	Fields []Field          `json:"fields"`
+	Open   bool             `json:"open"`
+}
+
+type Reading struct {
+	Name   string
+	Metric string
+	Kind   Kind
+	Value  string
+	Number *float64
+	Unit   string
+}
+
+func (r Reading) String() string {
+	return r.Value + r.Unit
+}
+
+func (s *Schema) field(name string) (*Field, bool) {
+	if name == "" {
+		return &s.Fields[0], true
+	}
+
+	for i := range s.Fields {
+		if strings.EqualFold(s.Fields[i].Name, name) {
+			return &s.Fields[i], true
+		}
+	}
+
+	if s.Open {
+		return &s.Fields[0], true
+	}
+
+	return nil, false
+}
+
+func (s *Schema) Parse(readings []codec.Reading) ([]Reading, error) {
+	if len(readings) == 0 {
+		return nil, fmt.Errorf("%w: %s payload has no readings", ErrInvalidReading, s.Type)
+	}
+
+	parsed := make([]Reading, 0, len(readings))
+
+	for _, reading := range readings {
+		field, ok := s.field(reading.Name)
+
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no reading named %q", ErrInvalidReading, s.Type, reading.Name)
+		}
+
+		value, err := field.parse(reading)
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %s: %v", ErrInvalidReading, s.Type, field.Name, err)
+		}
+
+		value.Name = reading.Name
+		value.Metric = field.Name
+
+		if s.Open && reading.Name != "" {
+			value.Metric = reading.Name
+		}
+		parsed = append(parsed, value)
+	}
+
+	return parsed, nil
+}
+
+func (f *Field) parse(reading codec.Reading) (Reading, error) {
+	raw := strings.TrimSpace(reading.Value)
+	result := Reading{Kind: f.Kind}
+
+	switch f.Kind {
+	case KIND_BINARY:
+		switch strings.ToLower(raw) {
+		case "1", "on", "true":
+			result.Value = "1"
+		case "0", "off", "false":
+			result.Value = "0"
+		default:
+			return result, fmt.Errorf("%q is not 0 or 1", raw)
+		}
+
+		number, _ := strconv.ParseFloat(result.Value, 64)
+		result.Number = &number
+	case KIND_BITS:
+		if !bitsPattern.MatchString(raw) {
+			return result, fmt.Errorf("%q is not a pattern of up to 8 bits", raw)
+		}
+
+		result.Value = raw
+	case KIND_NUMBER:
+		number, suffix, ok := ParseNumber(raw)
+
+		if !ok {
+			return result, fmt.Errorf("%q is not a number", raw)
+		}
+
+		unit, err := f.unit(suffix, reading.Unit)
+
+		if err != nil {
+			return result, err
+		}
+
+		if f.Min != nil && number < *f.Min {
+			return result, fmt.Errorf("%v is below %v", number, *f.Min)
+		}
+
+		if f.Max != nil && number > *f.Max {
+			return result, fmt.Errorf("%v is above %v", number, *f.Max)
+		}
+
+		result.Value = strconv.FormatFloat(number, 'f', -1, 64)
+		result.Number = &number
+		result.Unit = unit
+	case KIND_ENUM:
+		value := strings.ToUpper(raw)
+
+		for _, allowed := range f.Values {
+			if value == allowed {
+				result.Value = value
+				return result, nil
+			}
+		}
+
+		return result, fmt.Errorf("%q is not one of %s", raw, strings.Join(f.Values, ", "))
+	case KIND_IMAGE:
+		if len(raw) > maxTextLength || !imagePattern.MatchString(raw) {
+			return result, fmt.Errorf("%q is not a jpg or png image", raw)
+		}
+
+		result.Value = raw
+	default:
+		if raw == "" || len(raw) > maxTextLength {
+			return result, fmt.Errorf("text must be between 1 and %d characters", maxTextLength)
+		}
+
+		result.Value = raw
+		result.Unit = reading.Unit
+	}
+
+	return result, nil
+}
+
+func ParseNumber(raw string) (float64, string, bool) {
+	matches := numberPattern.FindStringSubmatch(raw)
+
+	if matches == nil {
+		return 0, "", false
+	}
+
+	number, err := strconv.ParseFloat(strings.Replace(matches[1], ",", ".", 1), 64)
+
+	if err != nil {
+		return 0, "", false
+	}
+
+	return number, matches[2], true
+}
+
+func (f *Field) unit(suffix, declared string) (string, error) {
+	unit := strings.TrimSpace(suffix)
+
+	if unit == "" {
+		unit = strings.TrimSpace(declared)
+	}
+
+	if unit == "" {
+		return f.Unit, nil
+	}
+
+	if unit == f.Unit {
+		return unit, nil
+	}
+
+	if canonical, ok := f.Aliases[strings.ToLower(unit)]; ok {
+		return canonical, nil
+	}
+
+	return "", fmt.Errorf("unit %q is not %s", unit, f.Unit)
+}
@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"errors"
+	"go/hioto/pkg/codec"
+	"go/hioto/pkg/enum"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		deviceType enum.EDeviceType
+		readings   []codec.Reading
+		metric     string
+		value      string
+		unit       string
+		number     *float64
+		wantErr    bool
+	}{
+		{
+			name:       "binary on",
+			deviceType: enum.AKTUATOR,
+			readings:   []codec.Reading{{Value: "ON"}},
+			metric:     "state",
+			value:      "1",
+			number:     bound(1),
+		},
+		{
+			name:       "binary invalid",
+			deviceType: enum.SENSOR_PARKING,
+			readings:   []codec.Reading{{Value: "maybe"}},
+			wantErr:    true,
+		},
+		{
+			name:       "bits pattern",
+			deviceType: enum.SENSOR,
+			readings:   []codec.Reading{{Value: "0101"}},
+			metric:     "pattern",
+			value:      "0101",
+		},
+		{
+			name:       "bits too long",
+			deviceType: enum.SENSOR,
+			readings:   []codec.Reading{{Value: "010101010"}},
+			wantErr:    true,
+		},
+		{
+			name:       "number with inline unit alias",
+			deviceType: enum.SENSOR_TEMPERATURE,
+			readings:   []codec.Reading{{Value: "23,5 C"}},
+			metric:     "temperature",
+			value:      "23.5",
+			unit:       "°C",
+			number:     bound(23.5),
+		},
+		{
+			name:       "number with declared unit",
+			deviceType: enum.SENSOR_TEMPERATURE,
+			readings:   []codec.Reading{{Name: "humidity", Value: "61", Unit: "%"}},
+			metric:     "humidity",
+			value:      "61",
+			unit:       "%",
+			number:     bound(61),
+		},
+		{
+			name:       "number out of range",
+			deviceType: enum.SENSOR_TEMPERATURE,
+			readings:   []codec.Reading{{Value: "200"}},
+			wantErr:    true,
+		},
+		{
+			name:       "number with wrong unit",
+			deviceType: enum.SENSOR_TEMPERATURE,
+			readings:   []codec.Reading{{Value: "20 K"}},
+			wantErr:    true,
+		},
+		{
+			name:       "unknown field",
+			deviceType: enum.SENSOR_TEMPERATURE,
+			readings:   []codec.Reading{{Name: "pressure", Value: "1"}},
+			wantErr:    true,
+		},
+		{
+			name:       "enum is normalised",
+			deviceType: enum.SENSOR_WATER_TANK,
+			readings:   []codec.Reading{{Name: "level", Value: "medium"}},
+			metric:     "level",
+			value:      "MEDIUM",
+		},
+		{
+			name:       "image",
+			deviceType: enum.SENSOR_CAMERA,
+			readings:   []codec.Reading{{Value: "captures/door.jpg"}},
+			metric:     "image",
+			value:      "captures/door.jpg",
+		},
+		{
+			name:       "image with wrong extension",
+			deviceType: enum.SENSOR_CAMERA,
+			readings:   []codec.Reading{{Value: "captures/door.gif"}},
+			wantErr:    true,
+		},
+		{
+			name:       "open schema keeps the reading name",
+			deviceType: enum.AI,
+			readings:   []codec.Reading{{Name: "label", Value: "person"}},
+			metric:     "label",
+			value:      "person",
+		},
+		{
+			name:       "unknown type falls back to text",
+			deviceType: enum.EDeviceType("CUSTOM"),
+			readings:   []codec.Reading{{Value: "hello"}},
+			metric:     "value",
+			value:      "hello",
+		},
+		{
+			name:       "no readings",
+			deviceType: enum.AKTUATOR,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readings, err := Parse(tt.deviceType, tt.readings)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidReading) {
+					t.Fatalf("error = %v, want ErrInvalidReading", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			reading := readings[0]
+
+			if reading.Metric != tt.metric || reading.Value != tt.value || reading.Unit != tt.unit {
+				t.Errorf("reading = %+v, want metric %q value %q unit %q", reading, tt.metric, tt.value, tt.unit)
+			}
+
+			if (reading.Number == nil) != (tt.number == nil) || (tt.number != nil && *reading.Number != *tt.number) {
+				t.Errorf("number = %v, want %v", reading.Number, tt.number)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/hioto/config"
+	"go/hioto/pkg/codec"
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	messagebroker "go/hioto/pkg/handler/message_broker"
+	"go/hioto/pkg/model"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAckTimeout    = 5 * time.Second
+	commandSweepInterval = time.Second
+)
+
+type CommandListener func(command *model.AktuatorCommand)
+
+type CommandService struct {
+	db            *gorm.DB
+	localBroker   messagebroker.Broker
+	timeout       time.Duration
+	ackRequired   bool
+	mu            sync.Mutex
+	waiters       map[string][]chan struct{}
+	listeners     []CommandListener
+	sentListeners []CommandListener
+}
+
+func NewCommandService(db *gorm.DB, localBroker messagebroker.Broker) *CommandService {
+	timeout, err := time.ParseDuration(config.AKTUATOR_ACK_TIMEOUT.GetValue())
+
+	if err != nil || timeout <= 0 {
+		timeout = defaultAckTimeout
+	}
+
+	return &CommandService{
+		db:          db,
+		localBroker: localBroker,
+		timeout:     timeout,
+		ackRequired: strings.EqualFold(config.AKTUATOR_ACK_REQUIRED.GetValue(), "true"),
+		waiters:     make(map[string][]chan struct{}),
+	}
+}
+
+func (s *CommandService) AckRequired() bool {
+	return s.ackRequired
+}
+
+func (s *CommandService) OnConfirmed(listener CommandListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *CommandService) OnSent(listener CommandListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sentListeners = append(s.sentListeners, listener)
+}
+
+func (s *CommandService) Dispatch(device *model.Registration, channel int, value string, source enum.ECommandSource) (*model.AktuatorCommand, error) {
+	guid := device.Guid
+	now := time.Now().In(location)
+	status := enum.COMMAND_SENT
+	expiresAt := now
+
+	if s.ackRequired {
+		status = enum.COMMAND_PENDING
+		expiresAt = now.Add(s.timeout)
+	}
+
+	command := &model.AktuatorCommand{
+		CorrelationID: uuid.NewString(),
+		Guid:          guid,
+		Channel:       channel,
+		Value:         value,
+		Source:        source,
+		Status:        status,
+		ExpiresAt:     expiresAt,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := s.db.Create(command).Error; err != nil {
+		log.Errorf("Error creating aktuator command: %v 💥", err)
+		return nil, fmt.Errorf("failed to create aktuator command: %w", err)
+	}
+
+	topic := config.AKTUATOR_TOPIC.GetValue()
+	hierarchical := device.TopicScheme == enum.TOPIC_SCHEME_HIERARCHICAL
+
+	if hierarchical {
+		topic = messagebroker.DeviceTopic(guid, messagebroker.DEVICE_TOPIC_SET)
+	}
+
+	wire := &codec.Command{
+		Guid:    guid,
+		Channel: channel,
+		Value:   value,
+	}
+
+	if s.ackRequired {
+		wire.CorrelationID = command.CorrelationID
+	}
+
+	message, err := codec.EncodeCommand(string(device.PayloadCodec), wire, !hierarchical)
+
+	if err != nil {
+		log.Errorf("Error encoding aktuator command %s: %v 💥", command.CorrelationID, err)
+
+		if _, finishErr := s.finish(command.CorrelationID, enum.COMMAND_FAILED, err.Error()); finishErr != nil {
+			log.Errorf("Error marking aktuator command %s as failed: %v 💥", command.CorrelationID, finishErr)
+		}
+
+		return nil, fmt.Errorf("failed to encode aktuator command: %w", err)
+	}
+
+	if err := s.localBroker.Publish(context.Background(), topic, message); err != nil {
+		log.Errorf("Error publishing aktuator command %s: %v 💥", command.CorrelationID, err)
+
+		if _, finishErr := s.finish(command.CorrelationID, enum.COMMAND_FAILED, err.Error()); finishErr != nil {
+			log.Errorf("Error marking aktuator command %s as failed: %v 💥", command.CorrelationID, finishErr)
+		}
+
+		return nil, fmt.Errorf("failed to publish aktuator command: %w", err)
+	}
+
+	log.Infof("Aktuator command %s sent to %s with value %s 📤", command.CorrelationID, channelAddress(guid, channel), value)
+
+	if !s.ackRequired {
+		s.mu.Lock()
+		listeners := append([]CommandListener(nil), s.sentListeners...)
+		s.mu.Unlock()
+
+		for _, listener := range listeners {
+			listener(command)
+		}
+	}
+
+	return command, nil
+}
+
+func (s *CommandService) Confirm(guid string, channel int, value, correlationID string, accepted bool) error {
+	var command model.AktuatorCommand
+
+	if err := s.db.Where("correlation_id = ?", correlationID).First(&command).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warnf("Acknowledgement for unknown aktuator command %s ignored", correlationID)
+			return nil
+		}
+
+		return fmt.Errorf("failed to fetch aktuator command %s: %w", correlationID, err)
+	}
+
+	if command.Guid != guid || command.Channel != channel || command.Value != value {
+		return fmt.Errorf(
+			"acknowledgement %s#%s does not match command %s (%s#%s)",
+			channelAddress(guid, channel), value, correlationID, channelAddress(command.Guid, command.Channel), command.Value,
+		)
+	}
+
+	status := enum.COMMAND_CONFIRMED
+	reason := ""
+
+	if !accepted {
+		status = enum.COMMAND_REJECTED
+		reason = "rejected by device"
+	}
+
+	confirmed, err := s.finish(correlationID, status, reason)
+
+	if err != nil {
+		return err
+	}
+
+	if confirmed == nil {
+		log.Warnf("Late acknowledgement for aktuator command %s ignored", correlationID)
+		return nil
+	}
+
+	log.Infof("Aktuator command %s %s by %s ✅", correlationID, strings.ToLower(string(status)), guid)
+
+	if status == enum.COMMAND_CONFIRMED {
+		s.mu.Lock()
+		listeners := append([]CommandListener(nil), s.listeners...)
+		s.mu.Unlock()
+
+		for _, listener := range listeners {
+			listener(confirmed)
+		}
+	}
+
+	return nil
+}
+
+func (s *CommandService) finish(correlationID string, status enum.ECommandStatus, reason string) (*model.AktuatorCommand, error) {
+	now := time.Now().In(location)
+
+	updates := map[string]any{
+		"status":     status,
+		"error":      reason,
+		"updated_at": now,
+	}
+
+	if status == enum.COMMAND_CONFIRMED || status == enum.COMMAND_REJECTED {
+		updates["confirmed_at"] = now
+	}
+
+	result := s.db.Model(&model.AktuatorCommand{}).
+		Where("correlation_id = ? AND status IN ?", correlationID, []enum.ECommandStatus{enum.COMMAND_PENDING, enum.COMMAND_SENT}).
+		Updates(updates)
+
+	if result.Error != nil {
+		log.Errorf("Error updating aktuator command %s: %v 💥", correlationID, result.Error)
+		return nil, fmt.Errorf("failed to update aktuator command %s: %w", correlationID, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	s.notify(correlationID)
+
+	var command model.AktuatorCommand
+
+	if err := s.db.Where("correlation_id = ?", correlationID).First(&command).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch aktuator command %s: %w", correlationID, err)
+	}
+
+	return &command, nil
+}
+
+func (s *CommandService) notify(correlationID string) {
+	s.mu.Lock()
+	waiters := s.waiters[correlationID]
+	delete(s.waiters, correlationID)
+	s.mu.Unlock()
+
+	for _, waiter := range waiters {
+		close(waiter)
+	}
+}
+
+func (s *CommandService) Wait(ctx context.Context, command *model.AktuatorCommand) (*model.AktuatorCommand, error) {
+	done := make(chan struct{})
+
+	s.mu.Lock()
+	s.waiters[command.CorrelationID] = append(s.waiters[command.CorrelationID], done)
+	s.mu.Unlock()
+
+	current, err := s.findCommand(command.CorrelationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Status == enum.COMMAND_PENDING {
+		timer := time.NewTimer(time.Until(current.ExpiresAt))
+		defer timer.Stop()
+
+		select {
+		case <-done:
+		case <-timer.C:
+			if _, err := s.finish(command.CorrelationID, enum.COMMAND_FAILED, "acknowledgement timeout"); err != nil {
+				return nil, err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return s.findCommand(command.CorrelationID)
+}
+
+func (s *CommandService) findCommand(correlationID string) (*model.AktuatorCommand, error) {
+	var command model.AktuatorCommand
+
+	if err := s.db.Where("correlation_id = ?", correlationID).First(&command).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch aktuator command %s: %w", correlationID, err)
+	}
+
+	return &command, nil
+}
+
+func (s *CommandService) GetCommand(correlationID string) (*dto.ResponseCommandDto, error) {
+	command, err := s.findCommand(correlationID)
+
+	if err != nil {
+		log.Errorf("Aktuator command not found: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusNotFound, "Aktuator command not found")
+	}
+
+	response := toCommandDto(command)
+
+	return &response, nil
+}
+
+func (s *CommandService) StartSweeper(ctx context.Context) {
+	ticker := time.NewTicker(commandSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var expired []model.AktuatorCommand
+
+		if err := s.db.Where("status = ? AND expires_at <= ?", enum.COMMAND_PENDING, time.Now().In(location)).
+			Find(&expired).Error; err != nil {
+			log.Errorf("Error fetching expired aktuator commands: %v 💥", err)
+			continue
+		}
+
+		for _, command := range expired {
+			if failed, err := s.finish(command.CorrelationID, enum.COMMAND_FAILED, "acknowledgement timeout"); err == nil && failed != nil {
+				log.Warnf("Aktuator command %s to %s timed out ⏱️", command.CorrelationID, command.Guid)
+			}
+		}
+	}
+}
+
+func toCommandDto(command *model.AktuatorCommand) dto.ResponseCommandDto {
+	return dto.ResponseCommandDto{
+		CorrelationID: command.CorrelationID,
+		Guid:          command.Guid,
+		Channel:       command.Channel,
+		Value:         command.Value,
+		Source:        command.Source,
+		Status:        command.Status,
+		Error:         command.Error,
+		ExpiresAt:     command.ExpiresAt,
+		ConfirmedAt:   command.ConfirmedAt,
+		CreatedAt:     command.CreatedAt,
+	}
+}
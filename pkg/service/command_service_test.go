@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"go/hioto/pkg/codec"
+	"go/hioto/pkg/enum"
+	"go/hioto/pkg/model"
+	"testing"
+	"time"
+)
+
+func TestCommandDispatchWithoutAck(t *testing.T) {
+	db, broker, commandService, _ := newTestControl(t, false)
+
+	device := createTestDevice(t, db, "relay-1", enum.AKTUATOR, 1)
+
+	command, err := commandService.Dispatch(device, 0, "1", enum.COMMAND_SOURCE_LOCAL)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if command.Status != enum.COMMAND_SENT {
+		t.Errorf("status = %s, want %s", command.Status, enum.COMMAND_SENT)
+	}
+
+	if published := broker.PublishedTo("aktuator"); len(published) != 1 || string(published[0].Body) != "relay-1#1" {
+		t.Errorf("published = %v, want relay-1#1", published)
+	}
+}
+
+func TestCommandDispatchTouchesPresence(t *testing.T) {
+	db, broker, commandService, _ := newTestControl(t, false)
+
+	NewPresenceService(db, NewOutboxService(db, broker), NewDeviceEventBus()).TrackCommands(commandService)
+
+	device := createTestDevice(t, db, "relay-1", enum.AKTUATOR, 1)
+
+	if _, err := commandService.Dispatch(device, 0, "1", enum.COMMAND_SOURCE_LOCAL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var updated model.Registration
+
+	db.Where("guid = ?", device.Guid).First(&updated)
+
+	if updated.LastSeen.IsZero() {
+		t.Error("last seen was not updated after a successful dispatch")
+	}
+}
+
+func TestCommandDispatchWithAck(t *testing.T) {
+	db, broker, commandService, _ := newTestControl(t, true)
+
+	device := createTestDevice(t, db, "relay-4", enum.AKTUATOR, 4)
+
+	tests := []struct {
+		name     string
+		channel  int
+		accepted bool
+		status   enum.ECommandStatus
+	}{
+		{name: "confirmed", channel: 2, accepted: true, status: enum.COMMAND_CONFIRMED},
+		{name: "rejected", channel: 3, accepted: false, status: enum.COMMAND_REJECTED},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker.Reset()
+
+			command, err := commandService.Dispatch(device, tt.channel, "1", enum.COMMAND_SOURCE_LOCAL)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if command.Status != enum.COMMAND_PENDING {
+				t.Errorf("status = %s, want %s", command.Status, enum.COMMAND_PENDING)
+			}
+
+			published := broker.PublishedTo("aktuator")
+
+			if len(published) != 1 {
+				t.Fatalf("published = %v, want one command", published)
+			}
+
+			wire, err := codec.DecodeCommand(published[0].Body)
+
+			if err != nil {
+				t.Fatalf("published command is invalid: %v", err)
+			}
+
+			if wire.Guid != device.Guid || wire.Channel != tt.channel || wire.Value != "1" || wire.CorrelationID != command.CorrelationID {
+				t.Errorf("wire = %+v, want channel %d and id %s", wire, tt.channel, command.CorrelationID)
+			}
+
+			done := make(chan *model.AktuatorCommand, 1)
+
+			go func() {
+				finished, _ := commandService.Wait(context.Background(), command)
+				done <- finished
+			}()
+
+			time.Sleep(20 * time.Millisecond)
+
+			if err := commandService.Confirm(device.Guid, tt.channel, "1", command.CorrelationID, tt.accepted); err != nil {
+				t.Fatalf("unexpected confirm error: %v", err)
+			}
+
+			select {
+			case finished := <-done:
+				if finished == nil || finished.Status != tt.status {
+					t.Errorf("finished = %+v, want %s", finished, tt.status)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("wait did not return after the acknowledgement")
+			}
+		})
+	}
+
+	var channels []model.AktuatorChannel
+
+	db.Where("device_guid = ? AND status = ?", device.Guid, "1").Find(&channels)
+
+	if len(channels) != 1 || channels[0].Channel != 2 {
+		t.Errorf("channels on = %+v, want only channel 2", channels)
+	}
+}
+
+func TestCommandWaitTimesOut(t *testing.T) {
+	db, _, commandService, _ := newTestControl(t, true)
+
+	device := createTestDevice(t, db, "relay-1", enum.AKTUATOR, 1)
+	commandService.timeout = 20 * time.Millisecond
+
+	command, err := commandService.Dispatch(device, 0, "1", enum.COMMAND_SOURCE_LOCAL)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	finished, err := commandService.Wait(context.Background(), command)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if finished.Status != enum.COMMAND_FAILED {
+		t.Errorf("status = %s, want %s", finished.Status, enum.COMMAND_FAILED)
+	}
+
+	if err := commandService.Confirm(device.Guid, 0, "1", command.CorrelationID, true); err != nil {
+		t.Errorf("a late acknowledgement should be ignored, got %v", err)
+	}
+}
+
+func TestCommandConfirmMismatch(t *testing.T) {
+	db, _, commandService, _ := newTestControl(t, true)
+
+	device := createTestDevice(t, db, "relay-1", enum.AKTUATOR, 1)
+
+	command, err := commandService.Dispatch(device, 0, "1", enum.COMMAND_SOURCE_LOCAL)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := commandService.Confirm(device.Guid, 0, "0", command.CorrelationID, true); err == nil {
+		t.Error("expected an error for a value mismatch")
+	}
+
+	if err := commandService.Confirm(device.Guid, 0, "1", "unknown", true); err != nil {
+		t.Errorf("unknown correlation id should be ignored, got %v", err)
+	}
+}
+
+func TestCommandDispatchHierarchicalTopic(t *testing.T) {
+	db, broker, commandService, _ := newTestControl(t, true)
+
+	t.Setenv("MAC_ADDRESS", "server-1")
+
+	device := createTestDevice(t, db, "relay-1", enum.AKTUATOR, 1)
+	device.TopicScheme = enum.TOPIC_SCHEME_HIERARCHICAL
+
+	command, err := commandService.Dispatch(device, 0, "1", enum.COMMAND_SOURCE_LOCAL)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if published := broker.PublishedTo("aktuator"); len(published) != 0 {
+		t.Errorf("published on the shared topic = %v, want nothing", published)
+	}
+
+	if published := broker.PublishedTo("hioto/server-1/relay-1/set"); len(published) != 1 || string(published[0].Body) != "1#"+command.CorrelationID {
+		t.Errorf("published = %v, want 1#%s", published, command.CorrelationID)
+	}
+}
@@ -1,201 +1,551 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"go/hioto/config"
 	"go/hioto/pkg/dto"
 	"go/hioto/pkg/enum"
-	messagebroker "go/hioto/pkg/handler/message_broker"
 	"go/hioto/pkg/model"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultBulkControlInterval = 250 * time.Millisecond
+
+	BULK_SCOPE_FLOOR = "floor"
+	BULK_SCOPE_ROOM  = "room"
 )
 
 type ControlDeviceService struct {
-	db *gorm.DB
+	db             *gorm.DB
+	commandService *CommandService
+	outboxService  *OutboxService
+	eventBus       *DeviceEventBus
+	bulkInterval   time.Duration
 }
 
-func NewControlDeviceService(db *gorm.DB) *ControlDeviceService {
-	return &ControlDeviceService{
-		db: db,
+func NewControlDeviceService(db *gorm.DB, commandService *CommandService, outboxService *OutboxService, eventBus *DeviceEventBus) *ControlDeviceService {
+	bulkInterval, err := time.ParseDuration(config.BULK_CONTROL_INTERVAL.GetValue())
+
+	if err != nil || bulkInterval < 0 {
+		bulkInterval = defaultBulkControlInterval
+	}
+
+	s := &ControlDeviceService{
+		db:             db,
+		commandService: commandService,
+		outboxService:  outboxService,
+		eventBus:       eventBus,
+		bulkInterval:   bulkInterval,
+	}
+
+	if commandService.AckRequired() {
+		commandService.OnConfirmed(s.applyConfirmedCommand)
 	}
+
+	return s
 }
 
-func (s *ControlDeviceService) ControlDeviceCloud(controlDto *dto.ControlLocalDto) {
-	var device model.Registration
-	value := strings.Split(controlDto.Message, "#")
+func parseControlMessage(message string) ([]string, error) {
+	value := strings.SplitN(message, "#", 2)
 
-	if err := s.db.Where("guid = ?", value[0]).First(&device).Error; err != nil {
-		log.Errorf("Device not found: %v 💥", err)
-		return
+	if len(value) != 2 || value[0] == "" || value[1] == "" {
+		return nil, fmt.Errorf("invalid control message %q, expected guid#value", message)
 	}
 
-	if controlDto.Type == enum.SENSOR {
-		s.ControlSensor(value[0], value[1])
-		return
+	return value, nil
+}
+
+func parseChannelAddress(address string) (string, int, error) {
+	index := strings.LastIndex(address, ":")
+
+	if index < 0 {
+		return address, 0, nil
 	}
 
-	location = time.FixedZone("WIB", 7*60*60)
+	channel, err := strconv.Atoi(address[index+1:])
 
-	tx := s.db.Begin()
+	if err != nil || channel < 1 || index == 0 {
+		return "", 0, fmt.Errorf("invalid address %q, expected guid or guid:channel", address)
+	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			log.Errorf("Transaction rollback due to panic: %v 💥", r)
-		} else {
-			if err := tx.Commit().Error; err != nil {
-				log.Errorf("Error committing transaction: %v 💥", err)
-				tx.Rollback()
-			}
+	return address[:index], channel, nil
+}
+
+func channelAddress(guid string, channel int) string {
+	if channel == 0 {
+		return guid
+	}
+
+	return guid + ":" + strconv.Itoa(channel)
+}
+
+func multiChannel(device *model.Registration) bool {
+	return device.Type == enum.AKTUATOR && device.Quantity > 1
+}
+
+func validateChannel(device *model.Registration, channel int) error {
+	if channel == 0 {
+		return nil
+	}
+
+	if device.Type != enum.AKTUATOR {
+		return fmt.Errorf("device %s is not an aktuator and has no channels", device.Guid)
+	}
+
+	if channel > device.Quantity {
+		return fmt.Errorf("device %s has %d channels, channel %d does not exist", device.Guid, device.Quantity, channel)
+	}
+
+	return nil
+}
+
+func deviceChannels(db *gorm.DB, device *model.Registration) []dto.ResponseChannelDto {
+	if !multiChannel(device) {
+		return nil
+	}
+
+	var rows []model.AktuatorChannel
+
+	if err := db.Where("device_guid = ? AND channel <= ?", device.Guid, device.Quantity).Find(&rows).Error; err != nil {
+		log.Errorf("Error getting channels of %s: %v 💥", device.Guid, err)
+	}
+
+	stored := make(map[int]model.AktuatorChannel, len(rows))
+
+	for _, row := range rows {
+		stored[row.Channel] = row
+	}
+
+	channels := make([]dto.ResponseChannelDto, 0, device.Quantity)
+
+	for channel := 1; channel <= device.Quantity; channel++ {
+		row, ok := stored[channel]
+
+		if !ok {
+			row = model.AktuatorChannel{Status: defaultChannelStatus(device), UpdatedAt: device.UpdatedAt}
 		}
-	}()
 
-	if tx.Error != nil {
-		log.Errorf("Error starting transaction: %v 💥", tx.Error)
-		return
+		channels = append(channels, dto.ResponseChannelDto{
+			Channel:   channel,
+			Status:    row.Status,
+			UpdatedAt: row.UpdatedAt,
+		})
 	}
 
-	status := value[1] == "1"
+	return channels
+}
+
+func defaultChannelStatus(device *model.Registration) string {
+	if device.Status == "1" {
+		return "1"
+	}
+
+	return "0"
+}
+
+func applyAktuatorState(tx *gorm.DB, device *model.Registration, channel int, value string) error {
+	now := time.Now().In(location)
+	status := value
+
+	if multiChannel(device) {
+		channels := deviceChannels(tx, device)
+		rows := make([]model.AktuatorChannel, 0, len(channels))
+		status = "0"
 
-	if err := tx.Model(&device).Updates(map[string]any{
+		for _, current := range channels {
+			if channel == 0 || current.Channel == channel {
+				current.Status = value
+				current.UpdatedAt = now
+			}
+
+			if current.Status == "1" {
+				status = "1"
+			}
+
+			rows = append(rows, model.AktuatorChannel{
+				DeviceGuid: device.Guid,
+				Channel:    current.Channel,
+				Status:     current.Status,
+				UpdatedAt:  current.UpdatedAt,
+			})
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "device_guid"}, {Name: "channel"}},
+			DoUpdates: clause.AssignmentColumns([]string{"status", "updated_at"}),
+		}).Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(&model.Registration{}).Where("guid = ?", device.Guid).Updates(map[string]any{
 		"status":     status,
-		"updated_at": time.Now().In(location),
+		"updated_at": now,
 	}).Error; err != nil {
-		log.Errorf("Error updating registration: %v 💥", err)
-		tx.Rollback()
-		return
+		return err
+	}
+
+	device.Status = status
+	device.UpdatedAt = now
+
+	return nil
+}
+
+func (s *ControlDeviceService) ControlDeviceCloud(controlDto *dto.ControlLocalDto) error {
+	var device model.Registration
+
+	value, err := parseControlMessage(controlDto.Message)
+
+	if err != nil {
+		return err
 	}
 
-	logEntry := model.LogAktuator{
-		InputGuid: value[0],
-		Name:      device.Name,
-		Value:     value[1],
-		Time:      time.Now().In(location),
+	guid, channel, err := parseChannelAddress(value[0])
+
+	if err != nil {
+		return err
 	}
 
-	if err := tx.Create(&logEntry).Error; err != nil {
-		log.Errorf("Error inserting log: %v 💥", err)
-		tx.Rollback()
-		return
+	if err := s.db.Where("guid = ?", guid).First(&device).Error; err != nil {
+		log.Errorf("Device not found: %v 💥", err)
+		return fmt.Errorf("device %s not found: %w", guid, err)
 	}
 
-	log.Info("Transaction committed successfully ✅")
+	if err := validateChannel(&device, channel); err != nil {
+		return err
+	}
 
-	messagebroker.PublishToMqtt(
-		config.MQTT_LOCAL_INSTANCE_NAME.GetValue(),
-		config.AKTUATOR_TOPIC.GetValue(),
-		controlDto.Message,
-	)
+	if controlDto.Type == enum.SENSOR {
+		return s.ControlSensor(guid, value[1])
+	}
+
+	if !s.commandService.AckRequired() {
+		if err := s.recordAktuatorState(&device, channel, value[1], false); err != nil {
+			return err
+		}
+
+		log.Info("Transaction committed successfully ✅")
+	}
+
+	if _, err := s.commandService.Dispatch(&device, channel, value[1], enum.COMMAND_SOURCE_CLOUD); err != nil {
+		log.Errorf("Error publishing control message: %v 💥", err)
+		return err
+	}
+
+	return nil
 }
 
-func (s *ControlDeviceService) ControlDeviceLocal(controlDto *dto.ControlLocalDto) error {
+func (s *ControlDeviceService) CheckWait(wait bool) error {
+	if wait && !s.commandService.AckRequired() {
+		return fiber.NewError(fiber.StatusBadRequest, "wait requires aktuator acknowledgements, set AKTUATOR_ACK_REQUIRED=true")
+	}
+
+	return nil
+}
+
+func (s *ControlDeviceService) ControlDeviceLocal(ctx context.Context, controlDto *dto.ControlLocalDto, wait bool) (*dto.ResponseCommandDto, error) {
 	var device model.Registration
-	value := strings.Split(controlDto.Message, "#")
 
-	if err := s.db.Where("guid = ?", value[0]).First(&device).Error; err != nil {
+	if err := s.CheckWait(wait); err != nil {
+		return nil, err
+	}
+
+	value, err := parseControlMessage(controlDto.Message)
+
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	guid, channel, err := parseChannelAddress(value[0])
+
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := s.db.Where("guid = ?", guid).First(&device).Error; err != nil {
 		log.Errorf("Device not found: %v 💥", err)
-		return fiber.NewError(fiber.StatusNotFound, "Device not found")
+		return nil, fiber.NewError(fiber.StatusNotFound, "Device not found")
+	}
+
+	if err := validateChannel(&device, channel); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
 	if controlDto.Type == enum.SENSOR {
-		s.ControlSensor(value[0], value[1])
-		return nil
+		if err := s.ControlSensor(guid, value[1]); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+
+		return nil, nil
+	}
+
+	if !s.commandService.AckRequired() {
+		if err := s.recordAktuatorState(&device, channel, value[1], true); err != nil {
+			return nil, err
+		}
+
+		log.Info("Transaction for local committed successfully ✅")
+	}
+
+	command, err := s.commandService.Dispatch(&device, channel, value[1], enum.COMMAND_SOURCE_LOCAL)
+
+	if err != nil {
+		log.Errorf("Error publishing control message: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusServiceUnavailable, "Error publishing control message")
+	}
+
+	response := toCommandDto(command)
+
+	if wait {
+		confirmed, err := s.waitForCommand(ctx, &response)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return confirmed, nil
 	}
 
-	location = time.FixedZone("WIB", 7*60*60)
+	return &response, nil
+}
+
+func (s *ControlDeviceService) ControlDevices(ctx context.Context, scope string, devices []model.Registration, value string, source enum.ECommandSource, wait bool) *dto.ResponseBulkControlDto {
+	response := &dto.ResponseBulkControlDto{
+		Value:   value,
+		Total:   len(devices),
+		Results: make([]dto.ResponseDeviceControlDto, len(devices)),
+	}
+
+	var wg sync.WaitGroup
+
+	dispatched := 0
+
+	for i := range devices {
+		device := &devices[i]
+		result := &response.Results[i]
 
-	tx := s.db.Begin()
+		result.Guid = device.Guid
+		result.Name = device.Name
 
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			log.Errorf("Transaction rollback due to panic: %v 💥", r)
-		} else {
-			if err := tx.Commit().Error; err != nil {
-				log.Errorf("Error committing the transaction: %v 💥", err)
-				tx.Rollback()
+		if device.Type != enum.AKTUATOR {
+			result.Skipped = true
+			result.Error = "device is not an aktuator"
+			continue
+		}
+
+		if dispatched > 0 && s.bulkInterval > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(s.bulkInterval):
 			}
 		}
-	}()
 
-	if tx.Error != nil {
-		log.Errorf("Error starting transaction: %v 💥", tx.Error)
-		return fiber.NewError(fiber.StatusBadRequest, "Error starting transaction")
+		if ctx.Err() != nil {
+			result.Error = "control cancelled"
+			continue
+		}
+
+		dispatched++
+
+		command, err := s.controlAktuator(device, 0, value, source)
+
+		if err != nil {
+			log.Errorf("Control of %s on %s to %s failed: %v 💥", device.Name, scope, value, err)
+			result.Error = err.Error()
+			continue
+		}
+
+		log.Infof("Control of %s on %s to %s sent ✅", device.Name, scope, value)
+
+		commandDto := toCommandDto(command)
+		result.Success = true
+		result.Command = &commandDto
+
+		if !wait {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			confirmed, err := s.waitForCommand(ctx, &commandDto)
+
+			if confirmed != nil {
+				result.Command = confirmed
+			}
+
+			if err != nil {
+				result.Success = false
+				result.Error = err.Error()
+			}
+		}()
 	}
 
-	status := value[1] == "1"
+	wg.Wait()
 
-	if err := tx.Model(&device).Updates(map[string]any{
-		"status":     status,
-		"updated_at": time.Now().In(location),
-	}).Error; err != nil {
-		log.Errorf("Error updating registration: %v 💥", err)
-		tx.Rollback()
-		return fiber.NewError(fiber.StatusBadRequest, "Error updating registration device")
+	for _, result := range response.Results {
+		switch {
+		case result.Skipped:
+			response.Skipped++
+		case result.Success:
+			response.Succeeded++
+		default:
+			response.Failed++
+		}
+	}
+
+	return response
+}
+
+func (s *ControlDeviceService) controlAktuator(device *model.Registration, channel int, value string, source enum.ECommandSource) (*model.AktuatorCommand, error) {
+	if !s.commandService.AckRequired() {
+		if err := s.recordAktuatorState(device, channel, value, source != enum.COMMAND_SOURCE_CLOUD); err != nil {
+			return nil, err
+		}
 	}
 
-	logEntry := model.LogAktuator{
-		InputGuid: value[0],
-		Name:      device.Name,
-		Value:     value[1],
-		Time:      time.Now().In(location),
+	return s.commandService.Dispatch(device, channel, value, source)
+}
+
+func (s *ControlDeviceService) PublishBulkResult(scope, guid string, response *dto.ResponseBulkControlDto, cause error) error {
+	result := dto.ResCloudBulkControlDto{
+		MacServer: config.MAC_ADDRESS.GetValue(),
+		Scope:     scope,
+		Guid:      guid,
+		Success:   cause == nil,
 	}
 
-	if err := tx.Create(&logEntry).Error; err != nil {
-		log.Errorf("Error inserting log: %v 💥", err)
-		tx.Rollback()
-		return fiber.NewError(fiber.StatusBadRequest, "Error inserting log")
+	if response != nil {
+		result.ResponseBulkControlDto = *response
+		result.Success = cause == nil && response.Failed == 0
 	}
 
-	log.Info("Transaction for local committed successfully ✅")
+	if result.Results == nil {
+		result.Results = []dto.ResponseDeviceControlDto{}
+	}
 
-	messagebroker.PublishToMqtt(
-		config.MQTT_LOCAL_INSTANCE_NAME.GetValue(),
-		config.AKTUATOR_TOPIC.GetValue(),
-		controlDto.Message,
+	if cause != nil {
+		result.Error = cause.Error()
+	}
+
+	body, err := json.Marshal(result)
+
+	if err != nil {
+		log.Errorf("Failed to marshal bulk control result: %v", err)
+		return err
+	}
+
+	return s.outboxService.Enqueue(
+		config.RMQ_CLOUD_INSTANCE.GetValue(),
+		body,
+		config.BULK_CONTROL_RESULT_QUEUE.GetValue(),
+		config.EXCHANGE_DIRECT.GetValue(),
 	)
+}
+
+func (s *ControlDeviceService) waitForCommand(ctx context.Context, pending *dto.ResponseCommandDto) (*dto.ResponseCommandDto, error) {
+	command, err := s.commandService.Wait(ctx, &model.AktuatorCommand{CorrelationID: pending.CorrelationID})
+
+	if err != nil {
+		log.Errorf("Error waiting for aktuator acknowledgement: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusServiceUnavailable, "Error waiting for aktuator acknowledgement")
+	}
+
+	response := toCommandDto(command)
+
+	switch command.Status {
+	case enum.COMMAND_REJECTED:
+		return &response, fiber.NewError(fiber.StatusConflict, "Aktuator rejected the command")
+	case enum.COMMAND_FAILED:
+		return &response, fiber.NewError(fiber.StatusGatewayTimeout, "Aktuator did not acknowledge the command")
+	}
+
+	return &response, nil
+}
+
+func (s *ControlDeviceService) recordAktuatorState(device *model.Registration, channel int, value string, notifyCloud bool) error {
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := applyAktuatorState(tx, device, channel, value); err != nil {
+			log.Errorf("Error updating registration: %v 💥", err)
+			return fiber.NewError(fiber.StatusBadRequest, "Error updating registration device")
+		}
+
+		logEntry := model.LogAktuator{
+			InputGuid: device.Guid,
+			Name:      device.Name,
+			Channel:   channel,
+			Value:     value,
+			Time:      time.Now().In(location),
+		}
+
+		if err := tx.Create(&logEntry).Error; err != nil {
+			log.Errorf("Error inserting log: %v 💥", err)
+			return fiber.NewError(fiber.StatusBadRequest, "Error inserting log")
+		}
+
+		if notifyCloud {
+			return s.publishUpdateResponseToCloud(tx, device)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
 
-	s.publishUpdateResponseToCloud(&device)
+	s.eventBus.Publish(enum.DEVICE_STATE_CHANGED, device)
 
 	return nil
 }
 
-func (s *ControlDeviceService) ControlSensor(guid, value string) {
+func (s *ControlDeviceService) applyConfirmedCommand(command *model.AktuatorCommand) {
+	var device model.Registration
+
+	if err := s.db.Where("guid = ?", command.Guid).First(&device).Error; err != nil {
+		log.Errorf("Device %s for confirmed command not found: %v 💥", command.Guid, err)
+		return
+	}
+
+	if err := s.recordAktuatorState(&device, command.Channel, command.Value, command.Source != enum.COMMAND_SOURCE_CLOUD); err != nil {
+		log.Errorf("Error applying confirmed command %s: %v 💥", command.CorrelationID, err)
+	}
+}
+
+func (s *ControlDeviceService) ControlSensor(guid, value string) error {
 	var ruleDevices []model.RuleDevice
 
 	if err := s.db.Where("input_guid = ?", guid).Where("input_value = ?", value).Find(&ruleDevices).Error; err != nil {
 		log.Errorf("Failed to fetch rule devices: %v 💥", err)
-		return
+		return fmt.Errorf("failed to fetch rule devices for %s: %w", guid, err)
 	}
 
 	if len(ruleDevices) == 0 {
 		log.Error("No rule on devices found 💥")
-		return
+		return nil
 	}
 
 	for _, ruleDevice := range ruleDevices {
 		var aktuator model.Registration
 
-		location = time.FixedZone("WIB", 7*60*60)
-
-		messageToAktuator := fmt.Sprintf("%s#%s", ruleDevice.OutputGuid, ruleDevice.OutputValue)
-
 		if err := s.db.Where("guid = ?", ruleDevice.OutputGuid).First(&aktuator).Error; err != nil {
 			log.Errorf("Failed to fetch aktuator: %v 💥", err)
 			continue
 		}
 
-		aktuator.Status = ruleDevice.OutputValue
-		aktuator.UpdatedAt = time.Now().In(location)
-
-		if err := s.db.Save(&aktuator).Error; err != nil {
-			log.Errorf("Failed update aktuator status: %v 💥", err)
+		if err := validateChannel(&aktuator, ruleDevice.OutputChannel); err != nil {
+			log.Errorf("Skipping rule %d: %v 💥", ruleDevice.ID, err)
 			continue
 		}
 
@@ -213,19 +563,38 @@ func (s *ControlDeviceService) ControlSensor(guid, value string) {
 			continue
 		}
 
-		messagebroker.PublishToMqtt(
-			config.MQTT_LOCAL_INSTANCE_NAME.GetValue(),
-			config.AKTUATOR_TOPIC.GetValue(),
-			messageToAktuator,
-		)
-
-		log.Infof("Sensor rule executed for aktuator %s with value %s ✅", aktuator.Name, ruleDevice.OutputValue)
+		if _, err := s.controlAktuator(&aktuator, ruleDevice.OutputChannel, ruleDevice.OutputValue, enum.COMMAND_SOURCE_RULE); err != nil {
+			log.Errorf("Error publishing message to aktuator: %v 💥", err)
+			continue
+		}
 
-		s.publishUpdateResponseToCloud(&aktuator)
+		log.Infof("Sensor rule executed for aktuator %s with value %s ✅", channelAddress(aktuator.Name, ruleDevice.OutputChannel), ruleDevice.OutputValue)
 	}
+
+	return nil
 }
 
-func (s *ControlDeviceService) publishUpdateResponseToCloud(device *model.Registration) error {
+func (s *ControlDeviceService) publishUpdateResponseToCloud(db *gorm.DB, device *model.Registration) error {
+	var (
+		roomGuid  *string
+		roomName  *string
+		floorID   *uint
+		floorGuid *string
+		floorName *string
+	)
+
+	if device.RoomID != nil {
+		var room model.Room
+
+		if err := db.Preload("Floor").First(&room, *device.RoomID).Error; err == nil {
+			roomGuid = &room.Guid
+			roomName = &room.Name
+			floorID = &room.Floor.ID
+			floorGuid = &room.Floor.Guid
+			floorName = &room.Floor.Name
+		}
+	}
+
 	bodyToCloud := dto.ResCloudDeviceDto{
 		ResponseDeviceDetailDto: dto.ResponseDeviceDetailDto{
 			ID:           device.ID,
@@ -238,10 +607,18 @@ func (s *ControlDeviceService) publishUpdateResponseToCloud(device *model.Regist
 			Minor:        device.Minor,
 			Status:       device.Status,
 			StatusDevice: string(device.StatusDevice),
+			TopicScheme:  device.TopicScheme,
+			PayloadCodec: device.PayloadCodec,
 			LastSeen:     device.LastSeen,
 			CreatedAt:    device.CreatedAt,
 			UpdatedAt:    device.UpdatedAt,
 			RoomID:       device.RoomID,
+			RoomGuid:     roomGuid,
+			RoomName:     roomName,
+			FloorID:      floorID,
+			FloorGuid:    floorGuid,
+			FloorName:    floorName,
+			Channels:     deviceChannels(db, device),
 		},
 		MacServer: config.MAC_ADDRESS.GetValue(),
 	}
@@ -253,12 +630,11 @@ func (s *ControlDeviceService) publishUpdateResponseToCloud(device *model.Regist
 		return fiber.NewError(fiber.StatusBadRequest, "Error marshaling JSON")
 	}
 
-	messagebroker.PublishToRmq(
+	return s.outboxService.EnqueueTx(
+		db,
 		config.RMQ_CLOUD_INSTANCE.GetValue(),
 		jsonBody,
 		config.UPDATE_RES_CLOUD.GetValue(),
 		config.EXCHANGE_DIRECT.GetValue(),
 	)
-
-	return nil
 }
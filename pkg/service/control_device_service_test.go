@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	messagebroker "go/hioto/pkg/handler/message_broker"
+	"go/hioto/pkg/model"
+	"go/hioto/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+
+	if err != nil {
+		t.Fatalf("failed to get database handle: %v", err)
+	}
+
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	utils.AutoMigrateDb(db)
+
+	return db
+}
+
+func newTestControl(t *testing.T, ackRequired bool) (*gorm.DB, *messagebroker.MemoryBroker, *CommandService, *ControlDeviceService) {
+	t.Helper()
+
+	ack := "false"
+
+	if ackRequired {
+		ack = "true"
+	}
+
+	t.Setenv("AKTUATOR_TOPIC", "aktuator")
+	t.Setenv("AKTUATOR_ACK_REQUIRED", ack)
+	t.Setenv("AKTUATOR_ACK_TIMEOUT", "1s")
+	t.Setenv("BULK_CONTROL_INTERVAL", "0s")
+
+	db := newTestDB(t)
+	broker := messagebroker.NewMemoryBroker("local")
+	commandService := NewCommandService(db, broker)
+	controlDeviceService := NewControlDeviceService(db, commandService, NewOutboxService(db, broker), NewDeviceEventBus())
+
+	return db, broker, commandService, controlDeviceService
+}
+
+func createTestDevice(t *testing.T, db *gorm.DB, guid string, deviceType enum.EDeviceType, quantity int) *model.Registration {
+	t.Helper()
+
+	device := &model.Registration{
+		Guid:     guid,
+		Mac:      guid + "-mac",
+		Type:     deviceType,
+		Name:     guid,
+		Quantity: quantity,
+		Status:   "0",
+	}
+
+	if err := db.Create(device).Error; err != nil {
+		t.Fatalf("failed to create device %s: %v", guid, err)
+	}
+
+	return device
+}
+
+func TestParseChannelAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		guid    string
+		channel int
+		wantErr bool
+	}{
+		{address: "relay-1", guid: "relay-1"},
+		{address: "relay-1:2", guid: "relay-1", channel: 2},
+		{address: "aa:bb:3", guid: "aa:bb", channel: 3},
+		{address: "relay-1:0", wantErr: true},
+		{address: "relay-1:-1", wantErr: true},
+		{address: "relay-1:x", wantErr: true},
+		{address: ":2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			guid, channel, err := parseChannelAddress(tt.address)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s and %d", guid, channel)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if guid != tt.guid || channel != tt.channel {
+				t.Errorf("got %s and %d, want %s and %d", guid, channel, tt.guid, tt.channel)
+			}
+		})
+	}
+}
+
+func TestControlDeviceLocal(t *testing.T) {
+	db, broker, _, controlDeviceService := newTestControl(t, false)
+
+	createTestDevice(t, db, "relay-1", enum.AKTUATOR, 1)
+	createTestDevice(t, db, "relay-4", enum.AKTUATOR, 4)
+
+	tests := []struct {
+		message string
+		payload string
+		guid    string
+		status  string
+	}{
+		{message: "relay-1#1", payload: "relay-1#1", guid: "relay-1", status: "1"},
+		{message: "relay-4:2#1", payload: "relay-4#2:1", guid: "relay-4", status: "1"},
+		{message: "relay-4#0", payload: "relay-4#0", guid: "relay-4", status: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			broker.Reset()
+
+			response, err := controlDeviceService.ControlDeviceLocal(context.Background(), &dto.ControlLocalDto{
+				Type:    enum.AKTUATOR,
+				Message: tt.message,
+			}, false)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if response.Status != enum.COMMAND_SENT {
+				t.Errorf("command status = %s, want %s", response.Status, enum.COMMAND_SENT)
+			}
+
+			published := broker.PublishedTo("aktuator")
+
+			if len(published) != 1 || string(published[0].Body) != tt.payload {
+				t.Fatalf("published = %v, want %q", published, tt.payload)
+			}
+
+			var device model.Registration
+
+			db.Where("guid = ?", tt.guid).First(&device)
+
+			if device.Status != tt.status {
+				t.Errorf("device status = %q, want %q", device.Status, tt.status)
+			}
+		})
+	}
+
+	var logs int64
+
+	db.Model(&model.LogAktuator{}).Count(&logs)
+
+	if logs != 3 {
+		t.Errorf("aktuator logs = %d, want 3", logs)
+	}
+
+	var channels []model.AktuatorChannel
+
+	db.Where("device_guid = ?", "relay-4").Order("channel ASC").Find(&channels)
+
+	if len(channels) != 4 {
+		t.Fatalf("channels = %d, want 4", len(channels))
+	}
+
+	for _, channel := range channels {
+		if channel.Status != "0" {
+			t.Errorf("channel %d status = %q, want 0", channel.Channel, channel.Status)
+		}
+	}
+}
+
+func TestControlDeviceLocalWithAck(t *testing.T) {
+	db, broker, commandService, controlDeviceService := newTestControl(t, true)
+
+	createTestDevice(t, db, "relay-1", enum.AKTUATOR, 1)
+
+	response, err := controlDeviceService.ControlDeviceLocal(context.Background(), &dto.ControlLocalDto{
+		Type:    enum.AKTUATOR,
+		Message: "relay-1#1",
+	}, false)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Status != enum.COMMAND_PENDING {
+		t.Errorf("command status = %s, want %s", response.Status, enum.COMMAND_PENDING)
+	}
+
+	var device model.Registration
+
+	db.Where("guid = ?", "relay-1").First(&device)
+
+	if device.Status != "0" {
+		t.Errorf("device status = %q before the acknowledgement, want 0", device.Status)
+	}
+
+	published := broker.PublishedTo("aktuator")
+
+	if len(published) != 1 || !strings.HasSuffix(string(published[0].Body), "#"+response.CorrelationID) {
+		t.Fatalf("published = %v, want the correlation id", published)
+	}
+
+	if err := commandService.Confirm("relay-1", 0, "1", response.CorrelationID, true); err != nil {
+		t.Fatalf("unexpected confirm error: %v", err)
+	}
+
+	db.Where("guid = ?", "relay-1").First(&device)
+
+	if device.Status != "1" {
+		t.Errorf("device status = %q after the acknowledgement, want 1", device.Status)
+	}
+}
+
+func TestControlDeviceLocalRejectsInvalidInput(t *testing.T) {
+	db, broker, _, controlDeviceService := newTestControl(t, false)
+
+	createTestDevice(t, db, "relay-1", enum.AKTUATOR, 1)
+
+	for _, message := range []string{"relay-1", "#1", "relay-1:2#1", "missing#1"} {
+		if _, err := controlDeviceService.ControlDeviceLocal(context.Background(), &dto.ControlLocalDto{
+			Type:    enum.AKTUATOR,
+			Message: message,
+		}, false); err == nil {
+			t.Errorf("%s: expected an error", message)
+		}
+	}
+
+	if published := broker.Published(); len(published) != 0 {
+		t.Errorf("published = %v, want nothing", published)
+	}
+}
+
+func TestControlDeviceLocalWaitRequiresAck(t *testing.T) {
+	db, broker, _, controlDeviceService := newTestControl(t, false)
+
+	createTestDevice(t, db, "relay-1", enum.AKTUATOR, 1)
+
+	_, err := controlDeviceService.ControlDeviceLocal(context.Background(), &dto.ControlLocalDto{
+		Type:    enum.AKTUATOR,
+		Message: "relay-1#1",
+	}, true)
+
+	var fiberErr *fiber.Error
+
+	if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusBadRequest {
+		t.Fatalf("error = %v, want a bad request", err)
+	}
+
+	if published := broker.Published(); len(published) != 0 {
+		t.Errorf("published = %v, want nothing", published)
+	}
+}
+
+func TestControlDevicesSkipsSensors(t *testing.T) {
+	db, broker, _, controlDeviceService := newTestControl(t, false)
+
+	devices := []model.Registration{
+		*createTestDevice(t, db, "relay-1", enum.AKTUATOR, 1),
+		*createTestDevice(t, db, "sensor-1", enum.SENSOR, 1),
+		*createTestDevice(t, db, "relay-2", enum.AKTUATOR, 1),
+	}
+
+	response := controlDeviceService.ControlDevices(context.Background(), BULK_SCOPE_ROOM, devices, "1", enum.COMMAND_SOURCE_LOCAL, false)
+
+	if response.Total != 3 || response.Succeeded != 2 || response.Skipped != 1 || response.Failed != 0 {
+		t.Errorf("response = %+v", response)
+	}
+
+	published := broker.PublishedTo("aktuator")
+
+	if len(published) != 2 || string(published[0].Body) != "relay-1#1" || string(published[1].Body) != "relay-2#1" {
+		t.Errorf("published = %v", published)
+	}
+}
+
+func TestControlSensorUsesRuleChannel(t *testing.T) {
+	db, broker, _, controlDeviceService := newTestControl(t, false)
+
+	createTestDevice(t, db, "sensor-1", enum.SENSOR, 1)
+	createTestDevice(t, db, "relay-4", enum.AKTUATOR, 4)
+
+	rule := model.RuleDevice{InputGuid: "sensor-1", InputValue: "1", OutputGuid: "relay-4", OutputChannel: 3, OutputValue: "1"}
+
+	if err := db.Create(&rule).Error; err != nil {
+		t.Fatalf("failed to create rule: %v", err)
+	}
+
+	if err := controlDeviceService.ControlSensor("sensor-1", "1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	published := broker.PublishedTo("aktuator")
+
+	if len(published) != 1 || string(published[0].Body) != "relay-4#3:1" {
+		t.Fatalf("published = %v, want %q", published, "relay-4#3:1")
+	}
+
+	var channel model.AktuatorChannel
+
+	db.Where("device_guid = ? AND channel = ?", "relay-4", 3).First(&channel)
+
+	if channel.Status != "1" {
+		t.Errorf("channel 3 status = %q, want 1", channel.Status)
+	}
+
+	var logs int64
+
+	db.Model(&model.LogAktuator{}).Where("channel = ?", 3).Count(&logs)
+
+	if logs != 1 {
+		t.Errorf("aktuator logs = %d, want 1", logs)
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"go/hioto/pkg/enum"
+	"go/hioto/pkg/model"
+	"sync"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type DeviceEvent struct {
+	Type   enum.EDeviceEvent
+	Device model.Registration
+}
+
+type DeviceEventListener func(event DeviceEvent)
+
+type DeviceEventBus struct {
+	mu        sync.RWMutex
+	listeners []DeviceEventListener
+}
+
+func NewDeviceEventBus() *DeviceEventBus {
+	return &DeviceEventBus{}
+}
+
+func (b *DeviceEventBus) Subscribe(listener DeviceEventListener) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.listeners = append(b.listeners, listener)
+}
+
+func (b *DeviceEventBus) Publish(eventType enum.EDeviceEvent, device *model.Registration) {
+	b.mu.RLock()
+	listeners := append([]DeviceEventListener(nil), b.listeners...)
+	b.mu.RUnlock()
+
+	event := DeviceEvent{Type: eventType, Device: *device}
+
+	for _, listener := range listeners {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Errorf("Device event listener panic on %s: %v 💥", eventType, r)
+				}
+			}()
+
+			listener(event)
+		}()
+	}
+}
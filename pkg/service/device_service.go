@@ -2,11 +2,13 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 	"go/hioto/config"
+	"go/hioto/pkg/codec"
 	"go/hioto/pkg/dto"
 	"go/hioto/pkg/enum"
-	messagebroker "go/hioto/pkg/handler/message_broker"
 	"go/hioto/pkg/model"
+	"go/hioto/pkg/schema"
 	"strings"
 	"time"
 
@@ -22,15 +24,35 @@ func init() {
 }
 
 type DeviceService struct {
-	db *gorm.DB
+	db            *gorm.DB
+	outboxService *OutboxService
+	eventBus      *DeviceEventBus
 }
 
-func NewDeviceService(db *gorm.DB) *DeviceService {
+func NewDeviceService(db *gorm.DB, outboxService *OutboxService, eventBus *DeviceEventBus) *DeviceService {
 	return &DeviceService{
-		db: db,
+		db:            db,
+		outboxService: outboxService,
+		eventBus:      eventBus,
 	}
 }
 
+func topicSchemeOrDefault(scheme enum.ETopicScheme) enum.ETopicScheme {
+	if scheme == "" {
+		return enum.TOPIC_SCHEME_LEGACY
+	}
+
+	return scheme
+}
+
+func payloadCodecOrDefault(payloadCodec enum.EPayloadCodec) enum.EPayloadCodec {
+	if payloadCodec == "" {
+		return enum.PAYLOAD_CODEC_TEXT
+	}
+
+	return payloadCodec
+}
+
 func (s *DeviceService) RegisterDeviceLocal(registrationDto *dto.RegistrationDto) (registrationResponse *dto.ResponseDeviceDetailDto, err error) {
 	var status string
 
@@ -39,18 +61,20 @@ func (s *DeviceService) RegisterDeviceLocal(registrationDto *dto.RegistrationDto
 	}
 
 	registration := &model.Registration{
-		Guid:      registrationDto.Guid,
-		Mac:       registrationDto.Mac,
-		Type:      registrationDto.Type,
-		Name:      registrationDto.Name,
-		Quantity:  registrationDto.Quantity,
-		Status:    status,
-		Version:   registrationDto.Version,
-		Minor:     registrationDto.Minor,
-		RoomID:    registrationDto.RoomID,
-		LastSeen:  time.Now().In(location),
-		CreatedAt: time.Now().In(location),
-		UpdatedAt: time.Now().In(location),
+		Guid:         registrationDto.Guid,
+		Mac:          registrationDto.Mac,
+		Type:         registrationDto.Type,
+		Name:         registrationDto.Name,
+		Quantity:     registrationDto.Quantity,
+		Status:       status,
+		Version:      registrationDto.Version,
+		Minor:        registrationDto.Minor,
+		RoomID:       registrationDto.RoomID,
+		TopicScheme:  topicSchemeOrDefault(registrationDto.TopicScheme),
+		PayloadCodec: payloadCodecOrDefault(registrationDto.PayloadCodec),
+		LastSeen:     time.Now().In(location),
+		CreatedAt:    time.Now().In(location),
+		UpdatedAt:    time.Now().In(location),
 	}
 
 	if err = s.db.Create(registration).Error; err != nil {
@@ -58,6 +82,8 @@ func (s *DeviceService) RegisterDeviceLocal(registrationDto *dto.RegistrationDto
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Error creating device")
 	}
 
+	s.eventBus.Publish(enum.DEVICE_REGISTERED, registration)
+
 	deviceResponse, err := s.GetDeviceByGuid(registration.Guid)
 	if err != nil {
 		log.Errorf("Error fetching created device: %v 💥", err)
@@ -76,19 +102,21 @@ func (s *DeviceService) RegisterDeviceLocal(registrationDto *dto.RegistrationDto
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Error marshaling JSON")
 	}
 
-	messagebroker.PublishToRmq(
+	if err := s.outboxService.Enqueue(
 		config.RMQ_CLOUD_INSTANCE.GetValue(),
 		jsonBody,
 		config.REGISTER_RES_CLOUD.GetValue(),
 		config.EXCHANGE_DIRECT.GetValue(),
-	)
+	); err != nil {
+		log.Errorf("Error queueing register response to cloud: %v 💥", err)
+	}
 
 	log.Infof("Device successfully registered from locals: %s ✅", registration.Name)
 
 	return deviceResponse, nil
 }
 
-func (s *DeviceService) RegisterDeviceCloud(registrationDto *dto.RegistrationDto) {
+func (s *DeviceService) RegisterDeviceCloud(registrationDto *dto.RegistrationDto) error {
 	var status string
 
 	if registrationDto.Type == enum.AKTUATOR {
@@ -96,30 +124,37 @@ func (s *DeviceService) RegisterDeviceCloud(registrationDto *dto.RegistrationDto
 	}
 
 	registration := &model.Registration{
-		Guid:      registrationDto.Guid,
-		Mac:       registrationDto.Mac,
-		Type:      registrationDto.Type,
-		Name:      registrationDto.Name,
-		Quantity:  registrationDto.Quantity,
-		Status:    status,
-		Version:   registrationDto.Version,
-		Minor:     registrationDto.Minor,
-		CreatedAt: time.Now().In(location),
-		UpdatedAt: time.Now().In(location),
+		Guid:         registrationDto.Guid,
+		Mac:          registrationDto.Mac,
+		Type:         registrationDto.Type,
+		Name:         registrationDto.Name,
+		Quantity:     registrationDto.Quantity,
+		Status:       status,
+		Version:      registrationDto.Version,
+		Minor:        registrationDto.Minor,
+		TopicScheme:  topicSchemeOrDefault(registrationDto.TopicScheme),
+		PayloadCodec: payloadCodecOrDefault(registrationDto.PayloadCodec),
+		LastSeen:     time.Now().In(location),
+		CreatedAt:    time.Now().In(location),
+		UpdatedAt:    time.Now().In(location),
 	}
 
 	if err := s.db.Create(registration).Error; err != nil {
 		log.Errorf("Error creating device: %v 💥", err)
-		return
+		return fmt.Errorf("error creating device %s: %w", registration.Guid, err)
 	}
 
+	s.eventBus.Publish(enum.DEVICE_REGISTERED, registration)
+
 	log.Infof("Your Device successfully registered from cloud: %s ✅", registration.Name)
+
+	return nil
 }
 
-func (s *DeviceService) GetAllDevice(deviceType, floorID, roomID string) ([]dto.ResponseDeviceListDto, error) {
+func (s *DeviceService) GetAllDevice(deviceType, floorID, roomID, tag string) ([]dto.ResponseDeviceListDto, error) {
 	var devices []model.Registration
 
-	var query *gorm.DB = s.db.Preload("Room.Floor")
+	var query *gorm.DB = s.db.Preload("Room.Floor").Preload("Groups")
 
 	if deviceType != "" {
 		query = query.Where("type = ?", deviceType)
@@ -134,6 +169,13 @@ func (s *DeviceService) GetAllDevice(deviceType, floorID, roomID string) ([]dto.
 		query = query.Where("room_id = ?", roomID)
 	}
 
+	if tag != "" {
+		query = query.Where("registrations.guid IN (?)", s.db.Table("device_group_members").
+			Select("device_group_members.device_guid").
+			Joins("JOIN device_groups ON device_groups.id = device_group_members.group_id").
+			Where("device_groups.tag = ?", normalizeTag(tag)))
+	}
+
 	query = query.Order("registrations.created_at DESC")
 
 	if err := query.Find(&devices).Error; err != nil {
@@ -149,6 +191,11 @@ func (s *DeviceService) GetAllDevice(deviceType, floorID, roomID string) ([]dto.
 			roomName = &device.Room.Name
 		}
 
+		tags := make([]string, 0, len(device.Groups))
+		for _, group := range device.Groups {
+			tags = append(tags, group.Tag)
+		}
+
 		result = append(result, dto.ResponseDeviceListDto{
 			ID:           device.ID,
 			Guid:         device.Guid,
@@ -160,10 +207,13 @@ func (s *DeviceService) GetAllDevice(deviceType, floorID, roomID string) ([]dto.
 			Minor:        device.Minor,
 			Status:       device.Status,
 			StatusDevice: string(device.StatusDevice),
+			TopicScheme:  device.TopicScheme,
+			PayloadCodec: device.PayloadCodec,
 			LastSeen:     device.LastSeen,
 			CreatedAt:    device.CreatedAt,
 			UpdatedAt:    device.UpdatedAt,
 			RoomName:     roomName,
+			Tags:         tags,
 		})
 	}
 
@@ -180,15 +230,19 @@ func (s *DeviceService) GetDeviceByGuid(guid string) (*dto.ResponseDeviceDetailD
 
 	var (
 		roomID    *uint
+		roomGuid  *string
 		roomName  *string
 		floorID   *uint
+		floorGuid *string
 		floorName *string
 	)
 
 	if device.Room != nil {
 		roomID = &device.Room.ID
+		roomGuid = &device.Room.Guid
 		roomName = &device.Room.Name
 		floorID = &device.Room.Floor.ID
+		floorGuid = &device.Room.Floor.Guid
 		floorName = &device.Room.Floor.Name
 	}
 
@@ -203,34 +257,41 @@ func (s *DeviceService) GetDeviceByGuid(guid string) (*dto.ResponseDeviceDetailD
 		Minor:        device.Minor,
 		Status:       device.Status,
 		StatusDevice: string(device.StatusDevice),
+		TopicScheme:  device.TopicScheme,
+		PayloadCodec: device.PayloadCodec,
 		LastSeen:     device.LastSeen,
 		CreatedAt:    device.CreatedAt,
 		UpdatedAt:    device.UpdatedAt,
 		RoomID:       roomID,
+		RoomGuid:     roomGuid,
 		RoomName:     roomName,
 		FloorID:      floorID,
+		FloorGuid:    floorGuid,
 		FloorName:    floorName,
+		Channels:     deviceChannels(s.db, &device),
 	}, nil
 }
 
-func (s *DeviceService) UpdateDeviceRMQCloud(updateDto *dto.ReqUpdateDeviceDto) {
+func (s *DeviceService) UpdateDeviceRMQCloud(updateDto *dto.ReqUpdateDeviceDto) error {
 	var device model.Registration
 
 	deviceRaw := s.db.Raw(`SELECT * FROM registrations WHERE guid = ?`, updateDto.Guid).Scan(&device)
 
 	if deviceRaw.RowsAffected == 0 {
 		log.Errorf("Device not found: %v 💥", deviceRaw.Error)
-		return
+		return fmt.Errorf("device %s not found", updateDto.Guid)
 	}
 
 	deviceUpdated, err := s.updateQuery(updateDto)
 
 	if err != nil {
 		log.Errorf("Error updating device: %v 💥", err)
-		return
+		return err
 	}
 
 	log.Infof("Device successfully updated: %s ✅", deviceUpdated.Name)
+
+	return nil
 }
 
 func (s *DeviceService) UpdateDeviceAPI(updateDto *dto.ReqUpdateDeviceDto) (*dto.ResponseDeviceDetailDto, error) {
@@ -258,12 +319,14 @@ func (s *DeviceService) UpdateDeviceAPI(updateDto *dto.ReqUpdateDeviceDto) (*dto
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Error marshaling JSON")
 	}
 
-	messagebroker.PublishToRmq(
+	if err := s.outboxService.Enqueue(
 		config.RMQ_CLOUD_INSTANCE.GetValue(),
 		jsonBody,
 		config.UPDATE_RES_CLOUD.GetValue(),
 		config.EXCHANGE_DIRECT.GetValue(),
-	)
+	); err != nil {
+		log.Errorf("Error queueing update response to cloud: %v 💥", err)
+	}
 
 	return deviceResponse, nil
 }
@@ -278,15 +341,27 @@ func (s *DeviceService) updateQuery(updateDto *dto.ReqUpdateDeviceDto) (*model.R
             version = ?,
             minor = ?,
             room_id = ?,
+            topic_scheme = COALESCE(?, topic_scheme),
+            payload_codec = COALESCE(?, payload_codec),
             updated_at = ?
         WHERE guid = ?
-	`, updateDto.Mac, updateDto.Type, updateDto.Quantity, updateDto.Name, updateDto.Version, updateDto.Minor, updateDto.RoomID, time.Now().In(location), updateDto.Guid)
+	`, updateDto.Mac, updateDto.Type, updateDto.Quantity, updateDto.Name, updateDto.Version, updateDto.Minor, updateDto.RoomID, updateDto.TopicScheme, updateDto.PayloadCodec, time.Now().In(location), updateDto.Guid)
 
 	if updateQuery.RowsAffected == 0 {
 		log.Errorf("Error updating device: %v 💥", updateQuery.Error)
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Error updating device")
 	}
 
+	if err := s.db.Where("device_guid = ? AND channel > ?", updateDto.Guid, updateDto.Quantity).Delete(&model.AktuatorChannel{}).Error; err != nil {
+		log.Errorf("Error deleting removed channels: %v 💥", err)
+	}
+
+	var device model.Registration
+
+	if err := s.db.Where("guid = ?", updateDto.Guid).First(&device).Error; err == nil {
+		s.eventBus.Publish(enum.DEVICE_UPDATED, &device)
+	}
+
 	return &model.Registration{
 		Mac:       updateDto.Mac,
 		Type:      updateDto.Type,
@@ -299,14 +374,28 @@ func (s *DeviceService) updateQuery(updateDto *dto.ReqUpdateDeviceDto) (*model.R
 	}, nil
 }
 
-func (s *DeviceService) DeleteDeviceRMQ(guid string) {
+func (s *DeviceService) DeleteDeviceRMQ(guid string) error {
 	if err := s.DeleteDevice(guid); err != nil {
 		log.Errorf("Error deleting device: %v 💥", err)
-		return
+		return err
 	}
+
+	return nil
 }
 
 func (s *DeviceService) DeleteDevice(guid string) error {
+	device, err := s.deleteDevice(guid)
+
+	if err != nil {
+		return err
+	}
+
+	s.eventBus.Publish(enum.DEVICE_DELETED, device)
+
+	return nil
+}
+
+func (s *DeviceService) deleteDevice(guid string) (deleted *model.Registration, err error) {
 	var device model.Registration
 
 	tx := s.db.Begin()
@@ -316,28 +405,34 @@ func (s *DeviceService) DeleteDevice(guid string) error {
 			tx.Rollback()
 			log.Errorf("Transaction rollback due to panic: %v 💥", r)
 		} else {
-			if err := tx.Commit().Error; err != nil {
-				log.Errorf("Error committing transaction: %v 💥", err)
+			if err != nil {
+				tx.Rollback()
+				return
+			}
+
+			if commitErr := tx.Commit().Error; commitErr != nil {
+				log.Errorf("Error committing transaction: %v 💥", commitErr)
 				tx.Rollback()
+				deleted, err = nil, fiber.NewError(fiber.StatusBadRequest, "Error deleting device")
 			}
 		}
 	}()
 
 	if tx.Error != nil {
 		log.Errorf("Error starting transaction: %v 💥", tx.Error)
-		return fiber.NewError(fiber.StatusBadGateway, "Error starting transaction")
+		return nil, fiber.NewError(fiber.StatusBadGateway, "Error starting transaction")
 	}
 
 	if err := tx.Where("guid = ?", guid).First(&device).Error; err != nil {
 		log.Error("Device not found 💥")
 		tx.Rollback()
-		return fiber.NewError(fiber.StatusNotFound, "Device not found")
+		return nil, fiber.NewError(fiber.StatusNotFound, "Device not found")
 	}
 
 	if err := tx.Delete(&device).Error; err != nil {
 		log.Errorf("Error deleting device: %v 💥", err)
 		tx.Rollback()
-		return fiber.NewError(fiber.StatusBadRequest, "Error deleting device")
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error deleting device")
 	}
 
 	switch device.Type {
@@ -345,14 +440,26 @@ func (s *DeviceService) DeleteDevice(guid string) error {
 		if err := tx.Where("input_guid = ?", guid).Delete(&model.RuleDevice{}).Error; err != nil {
 			log.Errorf("Error deleting rule devices: %v 💥", err)
 			tx.Rollback()
-			return fiber.NewError(fiber.StatusBadRequest, "Error deleting rule devices")
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Error deleting rule devices")
 		}
 	case enum.AKTUATOR:
 		if err := tx.Where("output_guid = ?", guid).Delete(&model.RuleDevice{}).Error; err != nil {
 			log.Errorf("Error deleting rule devices: %v 💥", err)
 			tx.Rollback()
-			return fiber.NewError(fiber.StatusBadRequest, "Error deleting rule devices")
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Error deleting rule devices")
 		}
+
+		if err := tx.Where("device_guid = ?", guid).Delete(&model.AktuatorChannel{}).Error; err != nil {
+			log.Errorf("Error deleting aktuator channels: %v 💥", err)
+			tx.Rollback()
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Error deleting aktuator channels")
+		}
+	}
+
+	if err := tx.Table("device_group_members").Where("device_guid = ?", guid).Delete(nil).Error; err != nil {
+		log.Errorf("Error deleting group memberships: %v 💥", err)
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error deleting group memberships")
 	}
 
 	payloadToCloud := dto.ReqDeleteDeviceToCloudDto{
@@ -364,79 +471,130 @@ func (s *DeviceService) DeleteDevice(guid string) error {
 
 	if err != nil {
 		log.Errorf("Error marshaling JSON: %v 💥", err)
-		return fiber.NewError(fiber.StatusBadRequest, "Error marshaling JSON")
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error marshaling JSON")
 	}
 
-	messagebroker.PublishToRmq(
+	if err := s.outboxService.EnqueueTx(
+		tx,
 		config.RMQ_CLOUD_INSTANCE.GetValue(),
 		jsonBody,
 		config.DELETE_RES_CLOUD.GetValue(),
 		config.EXCHANGE_DIRECT.GetValue(),
-	)
+	); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
 	log.Infof("Device successfully deleted: %s ✅", guid)
-	return nil
+	return &device, nil
 }
 
-func (s *DeviceService) CheckInactiveDevice() {
-	ticker := time.NewTicker(60 * time.Second)
+func (s *DeviceService) UpdateStatusAsMonitoring(telemetry *codec.Telemetry) (err error) {
+	guid := telemetry.Guid
+	recordedAt := time.Now().In(location)
 
-	for {
-		<-ticker.C
-		treshold := time.Now().Add(-10 * time.Second)
-
-		err := s.db.Model(&model.Registration{}).
-			Where("last_seen < ?", treshold).
-			Update("status_device", enum.OFF).Error
-
-		if err != nil {
-			log.Errorf("Error checking for inactive device: %v 💥", err)
-		} else {
-			log.Infof("Inactive devices marked as offline 🔻")
-		}
+	if telemetry.Timestamp != nil {
+		recordedAt = telemetry.Timestamp.In(location)
 	}
-}
 
-func (s *DeviceService) UpdateStatusAsMonitoring(guid, payload string) {
+	var device model.Registration
+
 	tx := s.db.Begin()
 
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
 			log.Errorf("Transaction rollback due to panic: %v 💥", r)
+		} else if err != nil {
+			tx.Rollback()
+		} else if commitErr := tx.Commit().Error; commitErr != nil {
+			log.Errorf("Error committing transaction: %v 💥", commitErr)
+			tx.Rollback()
+			err = fmt.Errorf("error committing monitoring data for %s: %w", guid, commitErr)
 		} else {
-			if err := tx.Commit().Error; err != nil {
-				log.Errorf("Error committing transaction: %v 💥", err)
-				tx.Rollback()
-			}
+			s.eventBus.Publish(enum.DEVICE_STATE_CHANGED, &device)
 		}
 	}()
 
-	var device model.Registration
-
 	if err := tx.Where("guid = ?", guid).First(&device).Error; err != nil {
 		log.Errorf("Device not found: %v 💥", err)
-		return
+		return fmt.Errorf("device %s not found: %w", guid, err)
+	}
+
+	readings, err := schema.Parse(device.Type, telemetry.Readings)
+
+	if err != nil {
+		log.Errorf("Rejected reading from %s: %v 💥", guid, err)
+		return fmt.Errorf("rejected reading from %s: %w", guid, err)
+	}
+
+	payload, err := telemetryStatus(readings)
+
+	if err != nil {
+		log.Errorf("Error encoding telemetry status: %v 💥", err)
+		return fmt.Errorf("error encoding telemetry status for %s: %w", guid, err)
 	}
 
 	device.Status = payload
 
 	if err := tx.Save(&device).Error; err != nil {
 		log.Errorf("Error updating status device: %v 💥", err)
-		return
+		return fmt.Errorf("error updating status device %s: %w", guid, err)
 	}
 
-	MonitoringHistories := &model.MonitoringHistory{
-		DeviceGuid: device.Guid,
-		DeviceName: device.Name,
-		DeviceType: device.Type,
-		Value:      payload,
-		Time:       time.Now().In(location),
+	MonitoringHistories := make([]model.MonitoringHistory, 0, len(readings))
+
+	for _, reading := range readings {
+		MonitoringHistories = append(MonitoringHistories, model.MonitoringHistory{
+			DeviceGuid: device.Guid,
+			DeviceName: device.Name,
+			DeviceType: device.Type,
+			Name:       reading.Name,
+			Value:      reading.Value,
+			Unit:       reading.Unit,
+			Time:       recordedAt,
+		})
 	}
 
-	if err := tx.Create(MonitoringHistories).Error; err != nil {
+	if err := tx.Create(&MonitoringHistories).Error; err != nil {
 		log.Errorf("Error creating monitoring history: %v 💥", err)
+		return fmt.Errorf("error creating monitoring history for %s: %w", guid, err)
+	}
+
+	if points := telemetryPoints(MonitoringHistories, readings); len(points) > 0 {
+		if err := tx.Create(&points).Error; err != nil {
+			log.Errorf("Error creating telemetry points: %v 💥", err)
+			return fmt.Errorf("error creating telemetry points for %s: %w", guid, err)
+		}
 	}
 
 	log.Infof("Data Monitoring device %s successfully updated: %s ✅", strings.Split(device.Name, "-")[0], payload)
+
+	return nil
+}
+
+func (s *DeviceService) PayloadCodec(guid string) enum.EPayloadCodec {
+	var device model.Registration
+
+	if err := s.db.Select("payload_codec").Where("guid = ?", guid).First(&device).Error; err != nil {
+		return enum.PAYLOAD_CODEC_TEXT
+	}
+
+	return device.PayloadCodec
+}
+
+func telemetryStatus(readings []schema.Reading) (string, error) {
+	if len(readings) == 1 && readings[0].Name == "" {
+		return readings[0].String(), nil
+	}
+
+	values := make(map[string]string, len(readings))
+
+	for _, reading := range readings {
+		values[reading.Name] = reading.String()
+	}
+
+	status, err := json.Marshal(values)
+
+	return string(status), err
 }
@@ -1,25 +1,39 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/hioto/config"
 	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
 	"go/hioto/pkg/model"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type FloorService struct {
-	db *gorm.DB
+	db                   *gorm.DB
+	outboxService        *OutboxService
+	controlDeviceService *ControlDeviceService
 }
 
-func NewFloorService(db *gorm.DB) *FloorService {
-	return &FloorService{db: db}
+func NewFloorService(db *gorm.DB, outboxService *OutboxService, controlDeviceService *ControlDeviceService) *FloorService {
+	return &FloorService{
+		db:                   db,
+		outboxService:        outboxService,
+		controlDeviceService: controlDeviceService,
+	}
 }
 
 func (s *FloorService) CreateFloor(createDto *dto.CreateFloorDto) (*dto.ResponseFloorDto, error) {
 	floor := &model.Floor{
+		Guid:      uuid.NewString(),
 		Name:      createDto.Name,
 		CreatedAt: time.Now().In(location),
 		UpdatedAt: time.Now().In(location),
@@ -30,8 +44,11 @@ func (s *FloorService) CreateFloor(createDto *dto.CreateFloorDto) (*dto.Response
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Error creating floor")
 	}
 
+	s.publishToCloud(enum.LAYOUT_CREATE, floor)
+
 	return &dto.ResponseFloorDto{
 		ID:        floor.ID,
+		Guid:      floor.Guid,
 		Name:      floor.Name,
 		CreatedAt: floor.CreatedAt,
 		UpdatedAt: floor.UpdatedAt,
@@ -51,6 +68,7 @@ func (s *FloorService) GetAllFloors() ([]dto.ResponseAllFloorDto, error) {
 	for _, floor := range floors {
 		result = append(result, dto.ResponseAllFloorDto{
 			ID:        floor.ID,
+			Guid:      floor.Guid,
 			Name:      floor.Name,
 			CreatedAt: floor.CreatedAt,
 			UpdatedAt: floor.UpdatedAt,
@@ -72,6 +90,7 @@ func (s *FloorService) GetFloorByID(id string) (*dto.ResponseFloorDto, error) {
 	for _, room := range floor.Rooms {
 		rooms = append(rooms, dto.ResponseRoomDto{
 			ID:        room.ID,
+			Guid:      room.Guid,
 			Name:      room.Name,
 			FloorID:   room.FloorID,
 			CreatedAt: room.CreatedAt,
@@ -81,6 +100,7 @@ func (s *FloorService) GetFloorByID(id string) (*dto.ResponseFloorDto, error) {
 
 	return &dto.ResponseFloorDto{
 		ID:        floor.ID,
+		Guid:      floor.Guid,
 		Name:      floor.Name,
 		Rooms:     rooms,
 		CreatedAt: floor.CreatedAt,
@@ -104,8 +124,11 @@ func (s *FloorService) UpdateFloor(id string, updateDto *dto.UpdateFloorDto) (*d
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Error updating floor")
 	}
 
+	s.publishToCloud(enum.LAYOUT_UPDATE, &floor)
+
 	return &dto.ResponseFloorDto{
 		ID:        floor.ID,
+		Guid:      floor.Guid,
 		Name:      floor.Name,
 		CreatedAt: floor.CreatedAt,
 		UpdatedAt: floor.UpdatedAt,
@@ -125,5 +148,126 @@ func (s *FloorService) DeleteFloor(id string) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Error deleting floor")
 	}
 
+	s.publishToCloud(enum.LAYOUT_DELETE, &floor)
+
+	return nil
+}
+
+func (s *FloorService) ApplyCloudFloor(reqDto *dto.ReqCloudFloorDto) error {
+	var floor model.Floor
+
+	err := s.db.Where("guid = ?", reqDto.Guid).First(&floor).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to find floor %s: %w", reqDto.Guid, err)
+	}
+
+	found := err == nil
+
+	if reqDto.Action == enum.LAYOUT_DELETE {
+		if !found {
+			log.Infof("Floor %s is already deleted ✅", reqDto.Guid)
+			return nil
+		}
+
+		if err := s.db.Delete(&floor).Error; err != nil {
+			return fmt.Errorf("error deleting floor %s: %w", reqDto.Guid, err)
+		}
+
+		log.Infof("Floor successfully deleted from cloud: %s ✅", floor.Name)
+
+		return nil
+	}
+
+	if !found {
+		floor = model.Floor{
+			Guid:      reqDto.Guid,
+			CreatedAt: time.Now().In(location),
+		}
+	}
+
+	floor.Name = reqDto.Name
+	floor.UpdatedAt = time.Now().In(location)
+
+	if err := s.db.Save(&floor).Error; err != nil {
+		return fmt.Errorf("error saving floor %s: %w", reqDto.Guid, err)
+	}
+
+	log.Infof("Floor successfully saved from cloud: %s ✅", floor.Name)
+
 	return nil
 }
+
+func (s *FloorService) ControlFloor(ctx context.Context, id string, controlDto *dto.ReqBulkControlDto, wait bool) (*dto.ResponseBulkControlDto, error) {
+	var floor model.Floor
+
+	if err := s.controlDeviceService.CheckWait(wait); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.First(&floor, id).Error; err != nil {
+		log.Errorf("Floor not found: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusNotFound, "Floor not found")
+	}
+
+	return s.controlFloor(ctx, &floor, controlDto.Value, enum.COMMAND_SOURCE_LOCAL, wait)
+}
+
+func (s *FloorService) ControlCloudFloor(reqDto *dto.ReqCloudBulkControlDto) (*dto.ResponseBulkControlDto, error) {
+	var floor model.Floor
+
+	if err := s.db.Where("guid = ?", reqDto.Guid).First(&floor).Error; err != nil {
+		log.Errorf("Floor not found: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusNotFound, "Floor not found")
+	}
+
+	return s.controlFloor(context.Background(), &floor, reqDto.Value, enum.COMMAND_SOURCE_CLOUD, false)
+}
+
+func (s *FloorService) controlFloor(ctx context.Context, floor *model.Floor, value string, source enum.ECommandSource, wait bool) (*dto.ResponseBulkControlDto, error) {
+	var devices []model.Registration
+
+	if err := s.db.Joins("JOIN rooms ON rooms.id = registrations.room_id").
+		Where("rooms.floor_id = ? AND registrations.type = ?", floor.ID, enum.AKTUATOR).
+		Order("registrations.id ASC").
+		Find(&devices).Error; err != nil {
+		log.Errorf("Error getting aktuators of floor: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error getting aktuators of floor")
+	}
+
+	if len(devices) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Floor has no aktuators")
+	}
+
+	response := s.controlDeviceService.ControlDevices(ctx, "floor "+floor.Name, devices, value, source, wait)
+
+	log.Infof("Floor %s controlled with value %s: %d succeeded, %d failed ✅", floor.Name, value, response.Succeeded, response.Failed)
+
+	return response, nil
+}
+
+func (s *FloorService) publishToCloud(action enum.ELayoutAction, floor *model.Floor) {
+	body, err := json.Marshal(dto.ResCloudFloorDto{
+		Action:    action,
+		MacServer: config.MAC_ADDRESS.GetValue(),
+		ID:        floor.ID,
+		Guid:      floor.Guid,
+		Name:      floor.Name,
+		CreatedAt: floor.CreatedAt,
+		UpdatedAt: floor.UpdatedAt,
+	})
+
+	if err != nil {
+		log.Errorf("Error marshaling JSON: %v 💥", err)
+		return
+	}
+
+	if err := s.outboxService.Enqueue(
+		config.RMQ_CLOUD_INSTANCE.GetValue(),
+		body,
+		config.FLOOR_RES_CLOUD.GetValue(),
+		config.EXCHANGE_DIRECT.GetValue(),
+	); err != nil {
+		log.Errorf("Error queueing floor %s to cloud: %v 💥", action, err)
+	}
+}
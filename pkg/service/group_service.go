@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	"go/hioto/pkg/model"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type GroupService struct {
+	db                   *gorm.DB
+	controlDeviceService *ControlDeviceService
+}
+
+func NewGroupService(db *gorm.DB, controlDeviceService *ControlDeviceService) *GroupService {
+	return &GroupService{
+		db:                   db,
+		controlDeviceService: controlDeviceService,
+	}
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func (s *GroupService) CreateGroup(createDto *dto.CreateGroupDto) (*dto.ResponseGroupDto, error) {
+	devices, err := s.findDevices(createDto.DeviceGuids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	group := &model.DeviceGroup{
+		Tag:       normalizeTag(createDto.Tag),
+		Name:      createDto.Name,
+		Devices:   devices,
+		CreatedAt: time.Now().In(location),
+		UpdatedAt: time.Now().In(location),
+	}
+
+	if err := s.checkTag(group.Tag, 0); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Omit("Devices.*").Create(group).Error; err != nil {
+		log.Errorf("Error creating group: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error creating group")
+	}
+
+	log.Infof("Group %s created with %d devices ✅", group.Tag, len(devices))
+
+	return toGroupDto(group), nil
+}
+
+func (s *GroupService) GetAllGroups() ([]dto.ResponseGroupDto, error) {
+	var groups []model.DeviceGroup
+
+	if err := s.db.Preload("Devices").Order("tag ASC").Find(&groups).Error; err != nil {
+		log.Errorf("Error getting all groups: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error getting all groups")
+	}
+
+	var result []dto.ResponseGroupDto = []dto.ResponseGroupDto{}
+
+	for i := range groups {
+		result = append(result, *toGroupDto(&groups[i]))
+	}
+
+	return result, nil
+}
+
+func (s *GroupService) GetGroupByID(id string) (*dto.ResponseGroupDto, error) {
+	group, err := s.findGroup(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toGroupDto(group), nil
+}
+
+func (s *GroupService) UpdateGroup(id string, updateDto *dto.UpdateGroupDto) (*dto.ResponseGroupDto, error) {
+	group, err := s.findGroup(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	group.Tag = normalizeTag(updateDto.Tag)
+	group.Name = updateDto.Name
+	group.UpdatedAt = time.Now().In(location)
+
+	if err := s.checkTag(group.Tag, group.ID); err != nil {
+		return nil, err
+	}
+
+	var devices []model.Registration
+
+	if updateDto.DeviceGuids != nil {
+		if devices, err = s.findDevices(*updateDto.DeviceGuids); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Devices").Save(group).Error; err != nil {
+			return err
+		}
+
+		if updateDto.DeviceGuids == nil {
+			return nil
+		}
+
+		group.Devices = devices
+
+		return tx.Model(group).Omit("Devices.*").Association("Devices").Replace(devices)
+	}); err != nil {
+		log.Errorf("Error updating group: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error updating group")
+	}
+
+	return toGroupDto(group), nil
+}
+
+func (s *GroupService) DeleteGroup(id string) error {
+	group, err := s.findGroup(id)
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Select("Devices").Delete(group).Error; err != nil {
+		log.Errorf("Error deleting group: %v 💥", err)
+		return fiber.NewError(fiber.StatusBadRequest, "Error deleting group")
+	}
+
+	return nil
+}
+
+func (s *GroupService) AddDevice(id, guid string) (*dto.ResponseGroupDto, error) {
+	group, err := s.findGroup(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := s.findDevices([]string{guid})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(group).Omit("Devices.*").Association("Devices").Append(devices); err != nil {
+		log.Errorf("Error adding device to group: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error adding device to group")
+	}
+
+	return s.GetGroupByID(id)
+}
+
+func (s *GroupService) RemoveDevice(id, guid string) (*dto.ResponseGroupDto, error) {
+	group, err := s.findGroup(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(group).Association("Devices").Delete(&model.Registration{Guid: guid}); err != nil {
+		log.Errorf("Error removing device from group: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error removing device from group")
+	}
+
+	return s.GetGroupByID(id)
+}
+
+func (s *GroupService) ControlGroup(ctx context.Context, id string, controlDto *dto.ReqBulkControlDto, wait bool) (*dto.ResponseBulkControlDto, error) {
+	if err := s.controlDeviceService.CheckWait(wait); err != nil {
+		return nil, err
+	}
+
+	group, err := s.findGroup(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(group.Devices) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Group has no devices")
+	}
+
+	response := s.controlDeviceService.ControlDevices(ctx, "group "+group.Tag, group.Devices, controlDto.Value, enum.COMMAND_SOURCE_LOCAL, wait)
+
+	log.Infof("Group %s controlled with value %s: %d succeeded, %d failed, %d skipped ✅", group.Tag, controlDto.Value, response.Succeeded, response.Failed, response.Skipped)
+
+	return response, nil
+}
+
+func (s *GroupService) findGroup(id string) (*model.DeviceGroup, error) {
+	var group model.DeviceGroup
+
+	if err := s.db.Preload("Devices").First(&group, id).Error; err != nil {
+		log.Errorf("Group not found: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusNotFound, "Group not found")
+	}
+
+	return &group, nil
+}
+
+func (s *GroupService) findDevices(guids []string) ([]model.Registration, error) {
+	devices := []model.Registration{}
+
+	if len(guids) == 0 {
+		return devices, nil
+	}
+
+	if err := s.db.Where("guid IN ?", guids).Find(&devices).Error; err != nil {
+		log.Errorf("Error getting group devices: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error getting group devices")
+	}
+
+	found := make(map[string]bool, len(devices))
+
+	for _, device := range devices {
+		found[device.Guid] = true
+	}
+
+	for _, guid := range guids {
+		if !found[guid] {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Device "+guid+" not found")
+		}
+	}
+
+	return devices, nil
+}
+
+func (s *GroupService) checkTag(tag string, id uint) error {
+	var count int64
+
+	if err := s.db.Model(&model.DeviceGroup{}).Where("tag = ? AND id <> ?", tag, id).Count(&count).Error; err != nil {
+		log.Errorf("Error checking group tag: %v 💥", err)
+		return fiber.NewError(fiber.StatusBadRequest, "Error checking group tag")
+	}
+
+	if count > 0 {
+		return fiber.NewError(fiber.StatusConflict, "Group tag "+tag+" already exists")
+	}
+
+	return nil
+}
+
+func toGroupDto(group *model.DeviceGroup) *dto.ResponseGroupDto {
+	devices := make([]dto.ResponseGroupDeviceDto, 0, len(group.Devices))
+
+	for _, device := range group.Devices {
+		devices = append(devices, dto.ResponseGroupDeviceDto{
+			Guid:   device.Guid,
+			Name:   device.Name,
+			Type:   string(device.Type),
+			Status: device.Status,
+		})
+	}
+
+	return &dto.ResponseGroupDto{
+		ID:        group.ID,
+		Tag:       group.Tag,
+		Name:      group.Name,
+		Devices:   devices,
+		CreatedAt: group.CreatedAt,
+		UpdatedAt: group.UpdatedAt,
+	}
+}
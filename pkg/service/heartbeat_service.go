@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"go/hioto/config"
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	messagebroker "go/hioto/pkg/handler/message_broker"
+	"go/hioto/pkg/model"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultHeartbeatInterval = 30 * time.Second
+	heartbeatErrorWindow     = time.Hour
+	heartbeatPublishTimeout  = 5 * time.Second
+)
+
+type HeartbeatService struct {
+	db          *gorm.DB
+	cloudBroker messagebroker.Broker
+	interval    time.Duration
+	startedAt   time.Time
+}
+
+func NewHeartbeatService(db *gorm.DB, cloudBroker messagebroker.Broker) *HeartbeatService {
+	interval, err := time.ParseDuration(config.HEARTBEAT_INTERVAL.GetValue())
+
+	if err != nil || interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+
+	return &HeartbeatService{
+		db:          db,
+		cloudBroker: cloudBroker,
+		interval:    interval,
+		startedAt:   time.Now().In(location),
+	}
+}
+
+func (s *HeartbeatService) Enabled() bool {
+	return config.IsCloudMqttConfigured()
+}
+
+func (s *HeartbeatService) Schedule(scheduler *Scheduler) {
+	if !s.Enabled() {
+		log.Warn("MQTT cloud is not configured, heartbeat is disabled")
+		return
+	}
+
+	config.OnConnectionStateChange(func(kind, instanceName string, state config.ConnectionState) {
+		if kind == "mqtt" && instanceName == s.cloudBroker.Name() && state == config.STATE_CONNECTED {
+			go s.Publish(context.Background())
+		}
+	})
+
+	scheduler.Every("heartbeat", s.interval, s.Publish)
+}
+
+func (s *HeartbeatService) Publish(ctx context.Context) error {
+	if !s.cloudBroker.IsConnected() {
+		return nil
+	}
+
+	heartbeat, err := s.BuildHeartbeat()
+
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(heartbeat)
+
+	if err != nil {
+		return err
+	}
+
+	return s.publish(ctx, body)
+}
+
+func (s *HeartbeatService) PublishOffline() {
+	if !s.Enabled() || !s.cloudBroker.IsConnected() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), heartbeatPublishTimeout)
+	defer cancel()
+
+	if err := s.publish(ctx, config.OfflineHeartbeat()); err != nil {
+		log.Errorf("Error publishing offline heartbeat: %v 💥", err)
+	}
+}
+
+func (s *HeartbeatService) publish(ctx context.Context, body []byte) error {
+	return s.cloudBroker.Publish(
+		ctx,
+		config.HeartbeatTopic(),
+		body,
+		messagebroker.WithQoS(1),
+		messagebroker.WithRetain(),
+	)
+}
+
+func (s *HeartbeatService) BuildHeartbeat() (*dto.HeartbeatDto, error) {
+	now := time.Now().In(location)
+	since := now.Add(-heartbeatErrorWindow)
+
+	heartbeat := &dto.HeartbeatDto{
+		MacServer:     config.MAC_ADDRESS.GetValue(),
+		Status:        config.HEARTBEAT_ONLINE,
+		Version:       config.Version,
+		StartedAt:     s.startedAt,
+		Timestamp:     now,
+		UptimeSeconds: int64(now.Sub(s.startedAt).Seconds()),
+		DbSizeBytes:   databaseSize(config.DB_PATH.GetValue()),
+		Mqtt:          map[string]string{},
+		Rmq:           map[string]string{},
+		Errors:        dto.HeartbeatErrorsDto{Window: heartbeatErrorWindow.String()},
+	}
+
+	for name, state := range config.GetMqttStates() {
+		heartbeat.Mqtt[name] = string(state)
+	}
+
+	for name, state := range config.GetRMQStates() {
+		heartbeat.Rmq[name] = string(state)
+	}
+
+	counts := []struct {
+		target *int64
+		query  *gorm.DB
+	}{
+		{&heartbeat.Devices.Total, s.db.Model(&model.Registration{})},
+		{&heartbeat.Devices.Online, s.db.Model(&model.Registration{}).Where("status_device = ?", enum.ON)},
+		{&heartbeat.OutboxBacklog, s.db.Model(&model.Outbox{}).Where("status = ?", enum.OUTBOX_PENDING)},
+		{&heartbeat.Errors.OutboxFailed, s.db.Model(&model.Outbox{}).Where("status = ? AND updated_at >= ?", enum.OUTBOX_FAILED, since)},
+		{&heartbeat.Errors.Quarantined, s.db.Model(&model.QuarantinedMessage{}).Where("created_at >= ?", since)},
+		{&heartbeat.Errors.CommandsFailed, s.db.Model(&model.AktuatorCommand{}).Where("status IN ? AND updated_at >= ?", []enum.ECommandStatus{enum.COMMAND_FAILED, enum.COMMAND_REJECTED}, since)},
+	}
+
+	for _, count := range counts {
+		if err := count.query.Count(count.target).Error; err != nil {
+			log.Errorf("Error building heartbeat: %v 💥", err)
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Error building heartbeat")
+		}
+	}
+
+	heartbeat.Devices.Offline = heartbeat.Devices.Total - heartbeat.Devices.Online
+
+	return heartbeat, nil
+}
+
+func databaseSize(path string) int64 {
+	var size int64
+
+	for _, file := range []string{path, path + "-wal"} {
+		if info, err := os.Stat(file); err == nil {
+			size += info.Size()
+		}
+	}
+
+	return size
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/hioto/config"
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	messagebroker "go/hioto/pkg/handler/message_broker"
+	"go/hioto/pkg/model"
+	"go/hioto/pkg/schema"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const defaultHomeAssistantPrefix = "homeassistant"
+
+var homeAssistantComponents = []string{"switch", "sensor", "binary_sensor"}
+
+type homeAssistantDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Model        string   `json:"model"`
+	SwVersion    string   `json:"sw_version"`
+	Manufacturer string   `json:"manufacturer"`
+	ViaDevice    string   `json:"via_device,omitempty"`
+}
+
+type homeAssistantConfig struct {
+	Name         string              `json:"name"`
+	UniqueID     string              `json:"unique_id"`
+	ObjectID     string              `json:"object_id"`
+	StateTopic   string              `json:"state_topic"`
+	CommandTopic string              `json:"command_topic,omitempty"`
+	PayloadOn    string              `json:"payload_on,omitempty"`
+	PayloadOff   string              `json:"payload_off,omitempty"`
+	StateOn      string              `json:"state_on,omitempty"`
+	StateOff     string              `json:"state_off,omitempty"`
+	DeviceClass  string              `json:"device_class,omitempty"`
+	Unit         string              `json:"unit_of_measurement,omitempty"`
+	Device       homeAssistantDevice `json:"device"`
+}
+
+type HomeAssistantService struct {
+	db                   *gorm.DB
+	localBroker          messagebroker.Broker
+	controlDeviceService *ControlDeviceService
+	enabled              bool
+	prefix               string
+	mu                   sync.Mutex
+	channels             map[string]int
+}
+
+func NewHomeAssistantService(
+	db *gorm.DB,
+	localBroker messagebroker.Broker,
+	controlDeviceService *ControlDeviceService,
+	eventBus *DeviceEventBus,
+) *HomeAssistantService {
+	prefix := strings.TrimSuffix(config.HA_DISCOVERY_PREFIX.GetValue(), "/")
+
+	if prefix == "" {
+		prefix = defaultHomeAssistantPrefix
+	}
+
+	s := &HomeAssistantService{
+		db:                   db,
+		localBroker:          localBroker,
+		controlDeviceService: controlDeviceService,
+		enabled:              strings.EqualFold(config.HA_DISCOVERY_ENABLED.GetValue(), "true"),
+		prefix:               prefix,
+		channels:             make(map[string]int),
+	}
+
+	if s.enabled {
+		eventBus.Subscribe(s.handleDeviceEvent)
+
+		config.OnConnectionStateChange(func(kind, instanceName string, state config.ConnectionState) {
+			if kind == "mqtt" && instanceName == localBroker.Name() && state == config.STATE_CONNECTED {
+				go s.PublishAll()
+			}
+		})
+	}
+
+	return s
+}
+
+func (s *HomeAssistantService) Enabled() bool {
+	return s.enabled
+}
+
+func (s *HomeAssistantService) StatusTopic() string {
+	return s.prefix + "/status"
+}
+
+func (s *HomeAssistantService) CommandTopicFilter() string {
+	return s.entityTopic("+", "set")
+}
+
+func (s *HomeAssistantService) entityTopic(guid, kind string) string {
+	return strings.Join([]string{messagebroker.DeviceTopicPrefix(), config.MAC_ADDRESS.GetValue(), "ha", guid, kind}, "/")
+}
+
+func (s *HomeAssistantService) parseCommandTopic(topic string) (string, bool) {
+	levels := strings.Split(topic, "/")
+
+	if len(levels) != 5 || levels[2] != "ha" || levels[4] != "set" || levels[3] == "" {
+		return "", false
+	}
+
+	return levels[3], true
+}
+
+func homeAssistantObjectID(value string) string {
+	return strings.NewReplacer(":", "", "-", "_", " ", "_").Replace(strings.ToLower(value))
+}
+
+func (s *HomeAssistantService) configTopic(component, guid string) string {
+	return fmt.Sprintf("%s/%s/hioto_%s/%s/config", s.prefix, component, homeAssistantObjectID(config.MAC_ADDRESS.GetValue()), homeAssistantObjectID(guid))
+}
+
+func (s *HomeAssistantService) channelConfigTopic(guid string, channel int) string {
+	return s.configTopic("switch", fmt.Sprintf("%s_ch%d", guid, channel))
+}
+
+func (s *HomeAssistantService) StartDiscovery(ctx context.Context) {
+	if !s.enabled {
+		return
+	}
+
+	s.PublishAll()
+}
+
+func (s *HomeAssistantService) PublishAll() {
+	var devices []model.Registration
+
+	if err := s.db.Find(&devices).Error; err != nil {
+		log.Errorf("Error getting devices for Home Assistant discovery: %v 💥", err)
+		return
+	}
+
+	for i := range devices {
+		s.publishDiscovery(&devices[i])
+		s.publishState(&devices[i])
+	}
+
+	log.Infof("Home Assistant discovery published for %d devices 🏠", len(devices))
+}
+
+func (s *HomeAssistantService) handleDeviceEvent(event DeviceEvent) {
+	switch event.Type {
+	case enum.DEVICE_REGISTERED, enum.DEVICE_UPDATED:
+		s.publishDiscovery(&event.Device)
+		s.publishState(&event.Device)
+	case enum.DEVICE_DELETED:
+		s.removeDiscovery(event.Device.Guid, "")
+		s.removeChannelDiscovery(event.Device.Guid, 0)
+	case enum.DEVICE_STATE_CHANGED:
+		s.publishState(&event.Device)
+	}
+}
+
+func (s *HomeAssistantService) componentFor(device *model.Registration) (string, homeAssistantConfig) {
+	entity := homeAssistantConfig{
+		Name:       device.Name,
+		UniqueID:   "hioto_" + homeAssistantObjectID(device.Guid),
+		ObjectID:   homeAssistantObjectID(device.Name),
+		StateTopic: s.entityTopic(device.Guid, "state"),
+		Device: homeAssistantDevice{
+			Identifiers:  []string{"hioto_" + homeAssistantObjectID(device.Guid)},
+			Name:         device.Name,
+			Model:        string(device.Type),
+			SwVersion:    device.Version + "." + device.Minor,
+			Manufacturer: "Hioto",
+			ViaDevice:    "hioto_" + homeAssistantObjectID(config.MAC_ADDRESS.GetValue()),
+		},
+	}
+
+	switch device.Type {
+	case enum.AKTUATOR:
+		entity.CommandTopic = s.entityTopic(device.Guid, "set")
+		entity.PayloadOn = "1"
+		entity.PayloadOff = "0"
+		entity.StateOn = "1"
+		entity.StateOff = "0"
+		return "switch", entity
+	case enum.SENSOR, enum.SENSOR_PARKING:
+		entity.PayloadOn = "1"
+		entity.PayloadOff = "0"
+		if device.Type == enum.SENSOR_PARKING {
+			entity.DeviceClass = "occupancy"
+		}
+		return "binary_sensor", entity
+	case enum.SENSOR_TEMPERATURE:
+		entity.DeviceClass = "temperature"
+		entity.Unit = "°C"
+		return "sensor", entity
+	default:
+		return "sensor", entity
+	}
+}
+
+func (s *HomeAssistantService) channelEntity(device *model.Registration, channel int) homeAssistantConfig {
+	_, entity := s.componentFor(device)
+	address := channelAddress(device.Guid, channel)
+
+	entity.Name = fmt.Sprintf("%s CH %d", device.Name, channel)
+	entity.UniqueID = fmt.Sprintf("%s_ch%d", entity.UniqueID, channel)
+	entity.ObjectID = fmt.Sprintf("%s_ch%d", entity.ObjectID, channel)
+	entity.StateTopic = s.entityTopic(address, "state")
+	entity.CommandTopic = s.entityTopic(address, "set")
+
+	return entity
+}
+
+func (s *HomeAssistantService) publishDiscovery(device *model.Registration) {
+	component, entity := s.componentFor(device)
+
+	s.removeDiscovery(device.Guid, component)
+	s.publishConfig(device.Guid, s.configTopic(component, device.Guid), entity)
+
+	channels := 0
+
+	if multiChannel(device) {
+		channels = device.Quantity
+	}
+
+	for channel := 1; channel <= channels; channel++ {
+		s.publishConfig(channelAddress(device.Guid, channel), s.channelConfigTopic(device.Guid, channel), s.channelEntity(device, channel))
+	}
+
+	s.removeChannelDiscovery(device.Guid, channels)
+}
+
+func (s *HomeAssistantService) publishConfig(address, topic string, entity homeAssistantConfig) {
+	payload, err := json.Marshal(entity)
+
+	if err != nil {
+		log.Errorf("Error marshaling Home Assistant discovery for %s: %v 💥", address, err)
+		return
+	}
+
+	if err := s.localBroker.Publish(
+		context.Background(),
+		topic,
+		payload,
+		messagebroker.WithQoS(1),
+		messagebroker.WithRetain(),
+	); err != nil {
+		log.Errorf("Error publishing Home Assistant discovery for %s: %v 💥", address, err)
+	}
+}
+
+func (s *HomeAssistantService) removeChannelDiscovery(guid string, keep int) {
+	s.mu.Lock()
+	published := s.channels[guid]
+
+	if keep > 0 {
+		s.channels[guid] = keep
+	} else {
+		delete(s.channels, guid)
+	}
+	s.mu.Unlock()
+
+	for channel := keep + 1; channel <= published; channel++ {
+		for _, topic := range []string{s.channelConfigTopic(guid, channel), s.entityTopic(channelAddress(guid, channel), "state")} {
+			if err := s.localBroker.Publish(
+				context.Background(),
+				topic,
+				[]byte{},
+				messagebroker.WithQoS(1),
+				messagebroker.WithRetain(),
+			); err != nil {
+				log.Errorf("Error removing Home Assistant channel %d of %s: %v 💥", channel, guid, err)
+			}
+		}
+	}
+}
+
+func (s *HomeAssistantService) removeDiscovery(guid, keep string) {
+	for _, component := range homeAssistantComponents {
+		if component == keep {
+			continue
+		}
+
+		if err := s.localBroker.Publish(
+			context.Background(),
+			s.configTopic(component, guid),
+			[]byte{},
+			messagebroker.WithQoS(1),
+			messagebroker.WithRetain(),
+		); err != nil {
+			log.Errorf("Error removing Home Assistant discovery for %s: %v 💥", guid, err)
+		}
+	}
+
+	if keep == "" {
+		if err := s.localBroker.Publish(
+			context.Background(),
+			s.entityTopic(guid, "state"),
+			[]byte{},
+			messagebroker.WithRetain(),
+		); err != nil {
+			log.Errorf("Error clearing Home Assistant state for %s: %v 💥", guid, err)
+		}
+	}
+}
+
+func homeAssistantState(device *model.Registration) (string, bool) {
+	if device.Type != enum.SENSOR_TEMPERATURE {
+		return device.Status, device.Status != ""
+	}
+
+	value := device.Status
+
+	if strings.HasPrefix(value, "{") {
+		var values map[string]string
+
+		if err := json.Unmarshal([]byte(value), &values); err != nil {
+			return "", false
+		}
+
+		deviceSchema, _ := schema.Lookup(device.Type)
+		value = values[deviceSchema.Fields[0].Name]
+	}
+
+	number, _, ok := schema.ParseNumber(value)
+
+	if !ok {
+		return "", false
+	}
+
+	return strconv.FormatFloat(number, 'f', -1, 64), true
+}
+
+func (s *HomeAssistantService) publishState(device *model.Registration) {
+	if state, ok := homeAssistantState(device); ok {
+		s.mirrorState(device.Guid, state)
+	}
+
+	for _, channel := range deviceChannels(s.db, device) {
+		s.mirrorState(channelAddress(device.Guid, channel.Channel), channel.Status)
+	}
+}
+
+func (s *HomeAssistantService) mirrorState(address, state string) {
+	if err := s.localBroker.Publish(
+		context.Background(),
+		s.entityTopic(address, "state"),
+		[]byte(state),
+		messagebroker.WithRetain(),
+	); err != nil {
+		log.Errorf("Error mirroring state of %s to Home Assistant: %v 💥", address, err)
+	}
+}
+
+func (s *HomeAssistantService) CommandHandler(delivery *messagebroker.Delivery) error {
+	guid, ok := s.parseCommandTopic(delivery.Destination)
+
+	if !ok {
+		return fmt.Errorf("invalid Home Assistant command topic %s", delivery.Destination)
+	}
+
+	value := strings.TrimSpace(string(delivery.Body))
+
+	switch strings.ToUpper(value) {
+	case "ON":
+		value = "1"
+	case "OFF":
+		value = "0"
+	}
+
+	_, err := s.controlDeviceService.ControlDeviceLocal(context.Background(), &dto.ControlLocalDto{
+		Type:    enum.AKTUATOR,
+		Message: guid + "#" + value,
+	}, false)
+
+	return err
+}
+
+func (s *HomeAssistantService) StatusHandler(message []byte) error {
+	if strings.EqualFold(strings.TrimSpace(string(message)), "online") {
+		s.PublishAll()
+	}
+
+	return nil
+}
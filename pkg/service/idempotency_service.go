@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/hioto/config"
+	"go/hioto/pkg/enum"
+	"go/hioto/pkg/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultDedupWindow = 24 * time.Hour
+	maxClaimAttempts   = 3
+)
+
+type IdempotencyService struct {
+	db     *gorm.DB
+	window time.Duration
+}
+
+func NewIdempotencyService(db *gorm.DB) *IdempotencyService {
+	window, err := time.ParseDuration(config.DEDUP_WINDOW.GetValue())
+
+	if err != nil || window <= 0 {
+		window = defaultDedupWindow
+	}
+
+	return &IdempotencyService{db: db, window: window}
+}
+
+func (s *IdempotencyService) Claim(messageID string, messageType enum.EMessageType, issuedAt *time.Time) (bool, error) {
+	for range maxClaimAttempts {
+		claimed, retry, err := s.tryClaim(messageID, messageType, issuedAt)
+
+		if !retry {
+			return claimed, err
+		}
+	}
+
+	return false, fmt.Errorf("failed to claim message %s after %d attempts", messageID, maxClaimAttempts)
+}
+
+func (s *IdempotencyService) tryClaim(messageID string, messageType enum.EMessageType, issuedAt *time.Time) (bool, bool, error) {
+	now := time.Now().In(location)
+	cutoff := now.Add(-s.window)
+
+	processed := &model.ProcessedMessage{
+		MessageID:   messageID,
+		Type:        messageType,
+		IssuedAt:    issuedAt,
+		ProcessedAt: now,
+	}
+
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(processed)
+
+	if result.Error != nil {
+		return false, false, result.Error
+	}
+
+	if result.RowsAffected == 1 {
+		return true, false, nil
+	}
+
+	var existing model.ProcessedMessage
+
+	if err := s.db.Where("message_id = ?", messageID).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, true, nil
+		}
+
+		return false, false, err
+	}
+
+	if existing.ProcessedAt.After(cutoff) {
+		return false, false, nil
+	}
+
+	update := s.db.Model(&model.ProcessedMessage{}).
+		Where("message_id = ? AND processed_at <= ?", messageID, cutoff).
+		Updates(map[string]any{"type": messageType, "issued_at": issuedAt, "processed_at": now})
+
+	if update.Error != nil {
+		return false, false, update.Error
+	}
+
+	return update.RowsAffected == 1, false, nil
+}
+
+func (s *IdempotencyService) Release(messageID string) {
+	if err := s.db.Where("message_id = ?", messageID).Delete(&model.ProcessedMessage{}).Error; err != nil {
+		log.Errorf("Error releasing processed message %s: %v 💥", messageID, err)
+	}
+}
+
+func (s *IdempotencyService) StartPruner(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cutoff := time.Now().Add(-s.window).In(location)
+
+		result := s.db.Where("processed_at < ?", cutoff).Delete(&model.ProcessedMessage{})
+
+		if result.Error != nil {
+			log.Errorf("Error pruning processed messages: %v 💥", result.Error)
+			continue
+		}
+
+		if result.RowsAffected > 0 {
+			log.Infof("Pruned %d processed messages older than %s 🧹", result.RowsAffected, s.window)
+		}
+	}
+}
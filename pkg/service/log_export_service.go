@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/hioto/config"
+	"go/hioto/pkg/dto"
+	messagebroker "go/hioto/pkg/handler/message_broker"
+	"go/hioto/pkg/model"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLogExportInterval  = 10 * time.Minute
+	defaultLogExportBatchSize = 500
+	defaultLogExportQueue     = "Log_export"
+
+	EXPORT_STREAM_LOG          = "log"
+	EXPORT_STREAM_LOG_AKTUATOR = "log_aktuator"
+	EXPORT_STREAM_MONITORING   = "monitoring_history"
+)
+
+type exportStream struct {
+	name  string
+	model any
+	fetch func(afterID uint, limit int) (items any, lastID uint, count int, err error)
+}
+
+type LogExportService struct {
+	db          *gorm.DB
+	cloudBroker messagebroker.Broker
+	interval    time.Duration
+	batchSize   int
+	streams     []exportStream
+	mu          sync.Mutex
+}
+
+func NewLogExportService(db *gorm.DB, cloudBroker messagebroker.Broker) *LogExportService {
+	interval, err := time.ParseDuration(config.LOG_EXPORT_INTERVAL.GetValue())
+
+	if err != nil || interval <= 0 {
+		interval = defaultLogExportInterval
+	}
+
+	batchSize, err := strconv.Atoi(config.LOG_EXPORT_BATCH_SIZE.GetValue())
+
+	if err != nil || batchSize <= 0 {
+		batchSize = defaultLogExportBatchSize
+	}
+
+	s := &LogExportService{
+		db:          db,
+		cloudBroker: cloudBroker,
+		interval:    interval,
+		batchSize:   batchSize,
+	}
+
+	s.streams = []exportStream{
+		{name: EXPORT_STREAM_LOG, model: &model.Log{}, fetch: s.fetchLogs},
+		{name: EXPORT_STREAM_LOG_AKTUATOR, model: &model.LogAktuator{}, fetch: s.fetchLogAktuators},
+		{name: EXPORT_STREAM_MONITORING, model: &model.MonitoringHistory{}, fetch: s.fetchMonitoring},
+	}
+
+	return s
+}
+
+func (s *LogExportService) Schedule(scheduler *Scheduler) {
+	scheduler.Every("log export", s.interval, s.Export)
+}
+
+func (s *LogExportService) Export(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.cloudBroker.IsConnected() {
+		log.Warn("Cloud is offline, log export postponed")
+		return nil
+	}
+
+	var errs []error
+
+	for _, stream := range s.streams {
+		if err := s.exportStream(ctx, stream); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", stream.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *LogExportService) ExportNow(ctx context.Context) ([]dto.ResponseExportCursorDto, error) {
+	if !s.cloudBroker.IsConnected() {
+		return nil, fiber.NewError(fiber.StatusServiceUnavailable, "Cloud is offline")
+	}
+
+	if err := s.Export(ctx); err != nil {
+		log.Errorf("Error exporting logs: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadGateway, "Error exporting logs")
+	}
+
+	return s.GetStatus()
+}
+
+func (s *LogExportService) exportStream(ctx context.Context, stream exportStream) error {
+	cursor, err := s.cursor(stream.name)
+
+	if err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		items, lastID, count, err := stream.fetch(cursor.LastID, s.batchSize)
+
+		if err != nil {
+			return fmt.Errorf("failed to read rows after %d: %w", cursor.LastID, err)
+		}
+
+		if count == 0 {
+			return nil
+		}
+
+		exportedAt := time.Now().In(location)
+
+		body, err := compressBatch(dto.LogExportBatchDto{
+			MacServer:  config.MAC_ADDRESS.GetValue(),
+			Stream:     stream.name,
+			FromID:     cursor.LastID + 1,
+			ToID:       lastID,
+			Count:      count,
+			ExportedAt: exportedAt,
+			Items:      items,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if err := s.cloudBroker.Publish(
+			ctx,
+			envOrDefault(config.LOG_EXPORT_QUEUE, defaultLogExportQueue),
+			body,
+			messagebroker.WithExchange(config.EXCHANGE_DIRECT.GetValue()),
+		); err != nil {
+			cursor.LastError = err.Error()
+			cursor.UpdatedAt = exportedAt
+			s.db.Save(cursor)
+
+			return fmt.Errorf("failed to publish batch up to %d: %w", lastID, err)
+		}
+
+		cursor.LastID = lastID
+		cursor.LastExportAt = &exportedAt
+		cursor.LastError = ""
+		cursor.UpdatedAt = exportedAt
+
+		if err := s.db.Save(cursor).Error; err != nil {
+			return fmt.Errorf("failed to advance export mark to %d: %w", lastID, err)
+		}
+
+		log.Infof("Exported %d %s rows up to %d 📤", count, stream.name, lastID)
+
+		if count < s.batchSize {
+			return nil
+		}
+	}
+
+	return ctx.Err()
+}
+
+func (s *LogExportService) cursor(stream string) (*model.ExportCursor, error) {
+	cursor := &model.ExportCursor{Stream: stream, UpdatedAt: time.Now().In(location)}
+
+	if err := s.db.FirstOrCreate(cursor, model.ExportCursor{Stream: stream}).Error; err != nil {
+		return nil, fmt.Errorf("failed to load export mark: %w", err)
+	}
+
+	return cursor, nil
+}
+
+func compressBatch(batch dto.LogExportBatchDto) ([]byte, error) {
+	body, err := json.Marshal(batch)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	var buffer bytes.Buffer
+
+	writer := gzip.NewWriter(&buffer)
+
+	if _, err := writer.Write(body); err != nil {
+		return nil, fmt.Errorf("failed to compress batch: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress batch: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (s *LogExportService) fetchLogs(afterID uint, limit int) (any, uint, int, error) {
+	var rows []model.Log
+
+	if err := s.db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, 0, 0, err
+	}
+
+	items := make([]dto.ExportLogDto, 0, len(rows))
+
+	for _, row := range rows {
+		items = append(items, dto.ExportLogDto{
+			ID:          row.ID,
+			InputGuid:   row.InputGuid,
+			InputName:   row.InputName,
+			InputValue:  row.InputValue,
+			OutputGuid:  row.OutputGuid,
+			OutputValue: row.OutputValue,
+			Time:        row.Time,
+		})
+	}
+
+	if len(rows) == 0 {
+		return items, afterID, 0, nil
+	}
+
+	return items, rows[len(rows)-1].ID, len(rows), nil
+}
+
+func (s *LogExportService) fetchLogAktuators(afterID uint, limit int) (any, uint, int, error) {
+	var rows []model.LogAktuator
+
+	if err := s.db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, 0, 0, err
+	}
+
+	items := make([]dto.ExportLogAktuatorDto, 0, len(rows))
+
+	for _, row := range rows {
+		items = append(items, dto.ExportLogAktuatorDto{
+			ID:      row.ID,
+			Guid:    row.InputGuid,
+			Channel: row.Channel,
+			Name:    row.Name,
+			Value:   row.Value,
+			Time:    row.Time,
+		})
+	}
+
+	if len(rows) == 0 {
+		return items, afterID, 0, nil
+	}
+
+	return items, rows[len(rows)-1].ID, len(rows), nil
+}
+
+func (s *LogExportService) fetchMonitoring(afterID uint, limit int) (any, uint, int, error) {
+	var rows []model.MonitoringHistory
+
+	if err := s.db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, 0, 0, err
+	}
+
+	items := make([]dto.ExportMonitoringDto, 0, len(rows))
+
+	for _, row := range rows {
+		items = append(items, dto.ExportMonitoringDto{
+			ID:         row.ID,
+			DeviceGuid: row.DeviceGuid,
+			DeviceName: row.DeviceName,
+			DeviceType: row.DeviceType,
+			Name:       row.Name,
+			Value:      row.Value,
+			Unit:       row.Unit,
+			Time:       row.Time,
+		})
+	}
+
+	if len(rows) == 0 {
+		return items, afterID, 0, nil
+	}
+
+	return items, rows[len(rows)-1].ID, len(rows), nil
+}
+
+func (s *LogExportService) GetStatus() ([]dto.ResponseExportCursorDto, error) {
+	result := make([]dto.ResponseExportCursorDto, 0, len(s.streams))
+
+	for _, stream := range s.streams {
+		cursor, err := s.cursor(stream.name)
+
+		if err != nil {
+			log.Errorf("Error getting export mark: %v 💥", err)
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Error getting export status")
+		}
+
+		var pending int64
+
+		if err := s.db.Model(stream.model).Where("id > ?", cursor.LastID).Count(&pending).Error; err != nil {
+			log.Errorf("Error counting pending %s rows: %v 💥", stream.name, err)
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Error getting export status")
+		}
+
+		result = append(result, dto.ResponseExportCursorDto{
+			Stream:       cursor.Stream,
+			LastID:       cursor.LastID,
+			Pending:      pending,
+			LastExportAt: cursor.LastExportAt,
+			LastError:    cursor.LastError,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/hioto/config"
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	messagebroker "go/hioto/pkg/handler/message_broker"
+	"go/hioto/pkg/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	outboxPollInterval = 5 * time.Second
+	outboxBaseBackoff  = 2 * time.Second
+	outboxMaxBackoff   = 5 * time.Minute
+	outboxMaxAttempts  = 20
+)
+
+type OutboxService struct {
+	db      *gorm.DB
+	brokers map[string]messagebroker.Broker
+	wake    chan struct{}
+}
+
+func NewOutboxService(db *gorm.DB, brokers ...messagebroker.Broker) *OutboxService {
+	brokerMap := make(map[string]messagebroker.Broker, len(brokers))
+
+	for _, broker := range brokers {
+		brokerMap[broker.Name()] = broker
+	}
+
+	return &OutboxService{
+		db:      db,
+		brokers: brokerMap,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+func (s *OutboxService) Enqueue(instanceName string, message []byte, queueName string, exchange string) error {
+	return s.EnqueueTx(s.db, instanceName, message, queueName, exchange)
+}
+
+func (s *OutboxService) EnqueueTx(tx *gorm.DB, instanceName string, message []byte, queueName string, exchange string) error {
+	entry := &model.Outbox{
+		InstanceName:  instanceName,
+		Exchange:      exchange,
+		QueueName:     queueName,
+		Payload:       string(message),
+		Status:        enum.OUTBOX_PENDING,
+		NextAttemptAt: time.Now().In(location),
+		CreatedAt:     time.Now().In(location),
+		UpdatedAt:     time.Now().In(location),
+	}
+
+	if err := tx.Create(entry).Error; err != nil {
+		log.Errorf("Error writing message to outbox: %v 💥", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Error writing message to outbox")
+	}
+
+	s.notify()
+
+	return nil
+}
+
+func (s *OutboxService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *OutboxService) StartDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	config.OnConnectionStateChange(func(kind, instanceName string, state config.ConnectionState) {
+		if state == config.STATE_CONNECTED {
+			s.notify()
+		}
+	})
+
+	log.Info("Outbox dispatcher started 📮")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Warn("Outbox dispatcher stopped")
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		s.drain(ctx)
+	}
+}
+
+func (s *OutboxService) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		var entry model.Outbox
+
+		err := s.db.
+			Where("status = ?", enum.OUTBOX_PENDING).
+			Where(
+				"NOT EXISTS (SELECT 1 FROM outboxes failed WHERE failed.status = ? AND failed.instance_name = outboxes.instance_name AND failed.exchange = outboxes.exchange AND failed.queue_name = outboxes.queue_name AND failed.id < outboxes.id)",
+				enum.OUTBOX_FAILED,
+			).
+			Order("id ASC").
+			First(&entry).Error
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return
+		}
+
+		if err != nil {
+			log.Errorf("Error reading outbox: %v 💥", err)
+			return
+		}
+
+		if entry.NextAttemptAt.After(time.Now()) {
+			return
+		}
+
+		broker, ok := s.brokers[entry.InstanceName]
+
+		if !ok {
+			s.scheduleRetry(&entry, fmt.Errorf("no broker registered for instance %s", entry.InstanceName))
+			continue
+		}
+
+		if !broker.IsConnected() {
+			return
+		}
+
+		if err := broker.Publish(
+			ctx,
+			entry.QueueName,
+			[]byte(entry.Payload),
+			messagebroker.WithExchange(entry.Exchange),
+		); err != nil {
+			s.scheduleRetry(&entry, err)
+			return
+		}
+
+		if err := s.db.Delete(&entry).Error; err != nil {
+			log.Errorf("Error removing dispatched outbox entry %d: %v 💥", entry.ID, err)
+			return
+		}
+	}
+}
+
+func (s *OutboxService) scheduleRetry(entry *model.Outbox, cause error) {
+	entry.Attempts++
+	entry.LastError = cause.Error()
+	entry.UpdatedAt = time.Now().In(location)
+
+	if entry.Attempts >= outboxMaxAttempts {
+		entry.Status = enum.OUTBOX_FAILED
+		log.Errorf("Outbox entry %d to %s failed after %d attempts, holding later messages to %s until it is retried 💥", entry.ID, entry.QueueName, entry.Attempts, entry.QueueName)
+	} else {
+		backoff := outboxBaseBackoff << (entry.Attempts - 1)
+
+		if backoff <= 0 || backoff > outboxMaxBackoff {
+			backoff = outboxMaxBackoff
+		}
+
+		entry.NextAttemptAt = time.Now().Add(backoff).In(location)
+		log.Warnf("Outbox entry %d to %s will be retried in %s", entry.ID, entry.QueueName, backoff)
+	}
+
+	if err := s.db.Save(entry).Error; err != nil {
+		log.Errorf("Error updating outbox entry %d: %v 💥", entry.ID, err)
+	}
+}
+
+func (s *OutboxService) GetOutbox(status string) ([]dto.ResponseOutboxDto, error) {
+	var entries []model.Outbox
+
+	query := s.db.Order("id ASC")
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Find(&entries).Error; err != nil {
+		log.Errorf("Error getting outbox entries: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error getting outbox entries")
+	}
+
+	var result []dto.ResponseOutboxDto = []dto.ResponseOutboxDto{}
+
+	for _, entry := range entries {
+		result = append(result, toOutboxDto(&entry))
+	}
+
+	return result, nil
+}
+
+func (s *OutboxService) RetryOutbox(id string) (*dto.ResponseOutboxDto, error) {
+	var entry model.Outbox
+
+	if err := s.db.First(&entry, id).Error; err != nil {
+		log.Errorf("Outbox entry not found: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusNotFound, "Outbox entry not found")
+	}
+
+	if entry.Status != enum.OUTBOX_FAILED {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Only failed outbox entries can be retried")
+	}
+
+	entry.Status = enum.OUTBOX_PENDING
+	entry.Attempts = 0
+	entry.NextAttemptAt = time.Now().In(location)
+	entry.UpdatedAt = time.Now().In(location)
+
+	if err := s.db.Save(&entry).Error; err != nil {
+		log.Errorf("Error retrying outbox entry: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error retrying outbox entry")
+	}
+
+	s.notify()
+
+	response := toOutboxDto(&entry)
+
+	return &response, nil
+}
+
+func toOutboxDto(entry *model.Outbox) dto.ResponseOutboxDto {
+	return dto.ResponseOutboxDto{
+		ID:            entry.ID,
+		InstanceName:  entry.InstanceName,
+		Exchange:      entry.Exchange,
+		QueueName:     entry.QueueName,
+		Payload:       entry.Payload,
+		Status:        entry.Status,
+		Attempts:      entry.Attempts,
+		LastError:     entry.LastError,
+		NextAttemptAt: entry.NextAttemptAt,
+		CreatedAt:     entry.CreatedAt,
+		UpdatedAt:     entry.UpdatedAt,
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"go/hioto/pkg/enum"
+	messagebroker "go/hioto/pkg/handler/message_broker"
+	"go/hioto/pkg/model"
+	"testing"
+)
+
+func TestOutboxHoldsDestinationBehindFailedEntry(t *testing.T) {
+	db := newTestDB(t)
+	broker := messagebroker.NewMemoryBroker("cloud")
+	outboxService := NewOutboxService(db, broker)
+
+	for _, queue := range []string{"update", "update", "log"} {
+		if err := outboxService.Enqueue("cloud", []byte(queue), queue, ""); err != nil {
+			t.Fatalf("enqueue %s: %v", queue, err)
+		}
+	}
+
+	if err := db.Model(&model.Outbox{}).Where("id = ?", 1).Update("status", enum.OUTBOX_FAILED).Error; err != nil {
+		t.Fatalf("failed to mark entry as failed: %v", err)
+	}
+
+	outboxService.drain(context.Background())
+
+	if published := broker.Published(); len(published) != 1 || published[0].Destination != "log" {
+		t.Fatalf("published = %v, want only the log entry", published)
+	}
+
+	if _, err := outboxService.RetryOutbox("1"); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	outboxService.drain(context.Background())
+
+	if published := broker.PublishedTo("update"); len(published) != 2 {
+		t.Fatalf("published to update = %d, want 2", len(published))
+	}
+
+	var remaining int64
+
+	db.Model(&model.Outbox{}).Count(&remaining)
+
+	if remaining != 0 {
+		t.Errorf("remaining outbox entries = %d, want 0", remaining)
+	}
+}
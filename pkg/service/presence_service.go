@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/hioto/config"
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	"go/hioto/pkg/model"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPresenceTimeout = 5 * time.Minute
+	defaultPresenceQueue   = "Presence_response"
+	presenceSweepInterval  = 15 * time.Second
+	presenceTouchInterval  = 5 * time.Second
+
+	PRESENCE_ONLINE  = "online"
+	PRESENCE_OFFLINE = "offline"
+)
+
+type PresenceService struct {
+	db             *gorm.DB
+	outboxService  *OutboxService
+	eventBus       *DeviceEventBus
+	defaultTimeout time.Duration
+	timeouts       map[enum.EDeviceType]time.Duration
+	mu             sync.Mutex
+	touched        map[string]time.Time
+}
+
+func NewPresenceService(db *gorm.DB, outboxService *OutboxService, eventBus *DeviceEventBus) *PresenceService {
+	defaultTimeout, err := time.ParseDuration(config.PRESENCE_DEFAULT_TIMEOUT.GetValue())
+
+	if err != nil || defaultTimeout <= 0 {
+		defaultTimeout = defaultPresenceTimeout
+	}
+
+	s := &PresenceService{
+		db:             db,
+		outboxService:  outboxService,
+		eventBus:       eventBus,
+		defaultTimeout: defaultTimeout,
+		timeouts:       parsePresenceTimeouts(config.PRESENCE_TIMEOUTS.GetValue()),
+		touched:        make(map[string]time.Time),
+	}
+
+	eventBus.Subscribe(func(event DeviceEvent) {
+		if event.Type == enum.DEVICE_DELETED {
+			s.mu.Lock()
+			delete(s.touched, event.Device.Guid)
+			s.mu.Unlock()
+		}
+	})
+
+	return s
+}
+
+func parsePresenceTimeouts(value string) map[enum.EDeviceType]time.Duration {
+	timeouts := make(map[enum.EDeviceType]time.Duration)
+
+	for _, entry := range strings.Split(value, ",") {
+		deviceType, duration, found := strings.Cut(strings.TrimSpace(entry), "=")
+
+		if !found {
+			continue
+		}
+
+		timeout, err := time.ParseDuration(strings.TrimSpace(duration))
+
+		if err != nil || timeout <= 0 {
+			log.Warnf("Invalid presence timeout %q ignored", entry)
+			continue
+		}
+
+		timeouts[enum.EDeviceType(strings.ToUpper(strings.TrimSpace(deviceType)))] = timeout
+	}
+
+	return timeouts
+}
+
+func (s *PresenceService) Timeout(deviceType enum.EDeviceType) time.Duration {
+	if timeout, ok := s.timeouts[deviceType]; ok {
+		return timeout
+	}
+
+	return s.defaultTimeout
+}
+
+func (s *PresenceService) TrackCommands(commandService *CommandService) {
+	commandService.OnSent(func(command *model.AktuatorCommand) {
+		s.Touch(command.Guid)
+	})
+}
+
+func (s *PresenceService) Schedule(scheduler *Scheduler) {
+	scheduler.Every("presence sweep", presenceSweepInterval, s.Sweep)
+}
+
+func (s *PresenceService) Touch(guid string) {
+	if guid == "" {
+		return
+	}
+
+	now := time.Now().In(location)
+
+	s.mu.Lock()
+	if last, ok := s.touched[guid]; ok && now.Sub(last) < presenceTouchInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.touched[guid] = now
+	s.mu.Unlock()
+
+	var device model.Registration
+
+	if err := s.db.Where("guid = ?", guid).First(&device).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Errorf("Error reading device %s for presence: %v 💥", guid, err)
+		}
+		return
+	}
+
+	if err := s.db.Model(&model.Registration{}).Where("guid = ?", guid).UpdateColumn("last_seen", now).Error; err != nil {
+		log.Errorf("Error updating last seen of %s: %v 💥", guid, err)
+		return
+	}
+
+	device.LastSeen = now
+
+	if device.StatusDevice != enum.ON {
+		if err := s.transition(&device, enum.ON, now); err != nil {
+			log.Errorf("Error marking %s online: %v 💥", guid, err)
+		}
+	}
+}
+
+func (s *PresenceService) Sweep(ctx context.Context) error {
+	var devices []model.Registration
+
+	if err := s.db.Where("status_device = ?", enum.ON).Find(&devices).Error; err != nil {
+		return fmt.Errorf("failed to read online devices: %w", err)
+	}
+
+	now := time.Now().In(location)
+
+	for i := range devices {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		device := &devices[i]
+		lastSeen := device.LastSeen
+
+		if lastSeen.IsZero() {
+			lastSeen = device.CreatedAt
+		}
+
+		if lastSeen.IsZero() || now.Sub(lastSeen) <= s.Timeout(device.Type) {
+			continue
+		}
+
+		if err := s.transition(device, enum.OFF, now); err != nil {
+			log.Errorf("Error marking %s offline: %v 💥", device.Guid, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *PresenceService) transition(device *model.Registration, status enum.EDeviceStatus, at time.Time) error {
+	presence := PRESENCE_OFFLINE
+	eventType := enum.DEVICE_OFFLINE
+
+	if status == enum.ON {
+		presence = PRESENCE_ONLINE
+		eventType = enum.DEVICE_ONLINE
+	}
+
+	changed := false
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Registration{}).
+			Where("guid = ? AND (status_device IS NULL OR status_device <> ?)", device.Guid, status).
+			UpdateColumn("status_device", status)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		changed = true
+
+		if err := tx.Create(&model.MonitoringHistory{
+			DeviceGuid: device.Guid,
+			DeviceName: device.Name,
+			DeviceType: device.Type,
+			Name:       "presence",
+			Value:      presence,
+			Time:       at,
+		}).Error; err != nil {
+			return err
+		}
+
+		body, err := json.Marshal(dto.PresenceEventDto{
+			MacServer: config.MAC_ADDRESS.GetValue(),
+			Guid:      device.Guid,
+			Name:      device.Name,
+			Type:      device.Type,
+			Status:    presence,
+			LastSeen:  device.LastSeen,
+			Time:      at,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return s.outboxService.EnqueueTx(
+			tx,
+			config.RMQ_CLOUD_INSTANCE.GetValue(),
+			body,
+			envOrDefault(config.PRESENCE_RES_CLOUD, defaultPresenceQueue),
+			config.EXCHANGE_DIRECT.GetValue(),
+		)
+	}); err != nil {
+		return err
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if status == enum.OFF {
+		s.mu.Lock()
+		delete(s.touched, device.Guid)
+		s.mu.Unlock()
+	}
+
+	device.StatusDevice = status
+	s.eventBus.Publish(eventType, device)
+
+	log.Infof("Device %s is %s 📶", device.Name, presence)
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	messagebroker "go/hioto/pkg/handler/message_broker"
+	"go/hioto/pkg/model"
+	"math"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type QuarantineService struct {
+	db       *gorm.DB
+	mu       sync.RWMutex
+	handlers map[string]messagebroker.DeliveryHandler
+}
+
+func NewQuarantineService(db *gorm.DB) *QuarantineService {
+	return &QuarantineService{
+		db:       db,
+		handlers: make(map[string]messagebroker.DeliveryHandler),
+	}
+}
+
+func quarantineRouteKey(brokerName, route string) string {
+	return brokerName + "|" + route
+}
+
+func (s *QuarantineService) Guard(brokerName, route string, handler messagebroker.DeliveryHandler) messagebroker.DeliveryHandler {
+	s.mu.Lock()
+	s.handlers[quarantineRouteKey(brokerName, route)] = handler
+	s.mu.Unlock()
+
+	return func(delivery *messagebroker.Delivery) error {
+		err := runHandler(handler, delivery)
+
+		if err == nil {
+			return nil
+		}
+
+		return s.Quarantine(brokerName, route, delivery.Destination, delivery.Body, err)
+	}
+}
+
+func runHandler(handler messagebroker.DeliveryHandler, delivery *messagebroker.Delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+
+	return handler(delivery)
+}
+
+func (s *QuarantineService) Quarantine(brokerName, route, destination string, payload []byte, cause error) error {
+	message := &model.QuarantinedMessage{
+		Broker:      brokerName,
+		Route:       route,
+		Destination: destination,
+		Payload:     append([]byte(nil), payload...),
+		Error:       cause.Error(),
+		Status:      enum.QUARANTINED,
+		CreatedAt:   time.Now().In(location),
+		UpdatedAt:   time.Now().In(location),
+	}
+
+	if err := s.db.Create(message).Error; err != nil {
+		log.Errorf("Error quarantining message from %s: %v 💥", destination, err)
+		return fmt.Errorf("failed to quarantine message: %w", err)
+	}
+
+	log.Warnf("Message from %s quarantined as #%d: %v ☣️", destination, message.ID, cause)
+
+	return nil
+}
+
+func (s *QuarantineService) GetAllQuarantine(params *dto.GetQuarantinePagination) (*model.MetaPagination, []dto.ResponseQuarantineDto, error) {
+	var messages []model.QuarantinedMessage
+	var totalData int64
+
+	query := s.db.Model(&model.QuarantinedMessage{})
+
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if params.Destination != "" {
+		query = query.Where("destination LIKE ?", params.Destination+"%")
+	}
+
+	if err := query.Count(&totalData).Error; err != nil {
+		log.Errorf("Error counting quarantined messages: %v 💥", err)
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Error getting quarantined messages")
+	}
+
+	offset := (params.Page - 1) * params.Limit
+
+	if err := query.Order("id DESC").Limit(params.Limit).Offset(offset).Find(&messages).Error; err != nil {
+		log.Errorf("Error getting quarantined messages: %v 💥", err)
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Error getting quarantined messages")
+	}
+
+	var result []dto.ResponseQuarantineDto = []dto.ResponseQuarantineDto{}
+
+	for _, message := range messages {
+		result = append(result, toQuarantineDto(&message))
+	}
+
+	meta := &model.MetaPagination{
+		Page:      params.Page,
+		Limit:     params.Limit,
+		TotalPage: int(math.Ceil(float64(totalData) / float64(params.Limit))),
+		TotalData: int(totalData),
+	}
+
+	return meta, result, nil
+}
+
+func (s *QuarantineService) findQuarantine(id string) (*model.QuarantinedMessage, error) {
+	var message model.QuarantinedMessage
+
+	if err := s.db.First(&message, id).Error; err != nil {
+		log.Errorf("Quarantined message not found: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusNotFound, "Quarantined message not found")
+	}
+
+	return &message, nil
+}
+
+func (s *QuarantineService) GetQuarantineByID(id string) (*dto.ResponseQuarantineDto, error) {
+	message, err := s.findQuarantine(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toQuarantineDto(message)
+
+	return &response, nil
+}
+
+func (s *QuarantineService) UpdateQuarantine(id string, updateDto *dto.UpdateQuarantineDto) (*dto.ResponseQuarantineDto, error) {
+	message, err := s.findQuarantine(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.Status != enum.QUARANTINED {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Only quarantined messages can be edited")
+	}
+
+	if updateDto.PayloadBase64 != nil {
+		payload, err := base64.StdEncoding.DecodeString(*updateDto.PayloadBase64)
+
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid base64 payload")
+		}
+
+		message.Payload = payload
+	} else {
+		message.Payload = []byte(*updateDto.Payload)
+	}
+
+	message.UpdatedAt = time.Now().In(location)
+
+	if err := s.db.Save(message).Error; err != nil {
+		log.Errorf("Error updating quarantined message: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error updating quarantined message")
+	}
+
+	response := toQuarantineDto(message)
+
+	return &response, nil
+}
+
+func (s *QuarantineService) ReinjectQuarantine(id string) (*dto.ResponseQuarantineDto, error) {
+	message, err := s.findQuarantine(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.Status != enum.QUARANTINED {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Message was already reinjected")
+	}
+
+	s.mu.RLock()
+	handler, ok := s.handlers[quarantineRouteKey(message.Broker, message.Route)]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("No handler registered for %s on %s", message.Route, message.Broker))
+	}
+
+	message.Attempts++
+	message.UpdatedAt = time.Now().In(location)
+
+	handleErr := runHandler(handler, messagebroker.NewDelivery(message.Destination, message.Payload, nil))
+
+	if handleErr != nil {
+		message.Error = handleErr.Error()
+	} else {
+		message.Status = enum.REINJECTED
+	}
+
+	if err := s.db.Save(message).Error; err != nil {
+		log.Errorf("Error updating quarantined message: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error updating quarantined message")
+	}
+
+	if handleErr != nil {
+		log.Errorf("Reinjecting quarantined message #%d failed: %v 💥", message.ID, handleErr)
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, handleErr.Error())
+	}
+
+	log.Infof("Quarantined message #%d reinjected to %s ✅", message.ID, message.Destination)
+
+	response := toQuarantineDto(message)
+
+	return &response, nil
+}
+
+func toQuarantineDto(message *model.QuarantinedMessage) dto.ResponseQuarantineDto {
+	var payload string
+
+	if utf8.Valid(message.Payload) {
+		payload = string(message.Payload)
+	}
+
+	return dto.ResponseQuarantineDto{
+		ID:            message.ID,
+		Broker:        message.Broker,
+		Route:         message.Route,
+		Destination:   message.Destination,
+		Payload:       payload,
+		PayloadBase64: base64.StdEncoding.EncodeToString(message.Payload),
+		Error:         message.Error,
+		Status:        message.Status,
+		Attempts:      message.Attempts,
+		CreatedAt:     message.CreatedAt,
+		UpdatedAt:     message.UpdatedAt,
+	}
+}
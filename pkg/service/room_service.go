@@ -1,25 +1,39 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/hioto/config"
 	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
 	"go/hioto/pkg/model"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type RoomService struct {
-	db *gorm.DB
+	db                   *gorm.DB
+	outboxService        *OutboxService
+	controlDeviceService *ControlDeviceService
 }
 
-func NewRoomService(db *gorm.DB) *RoomService {
-	return &RoomService{db: db}
+func NewRoomService(db *gorm.DB, outboxService *OutboxService, controlDeviceService *ControlDeviceService) *RoomService {
+	return &RoomService{
+		db:                   db,
+		outboxService:        outboxService,
+		controlDeviceService: controlDeviceService,
+	}
 }
 
 func (s *RoomService) CreateRoom(createDto *dto.CreateRoomDto) (*dto.ResponseRoomDto, error) {
 	room := &model.Room{
+		Guid:      uuid.NewString(),
 		Name:      createDto.Name,
 		FloorID:   createDto.FloorID,
 		CreatedAt: time.Now().In(location),
@@ -31,8 +45,11 @@ func (s *RoomService) CreateRoom(createDto *dto.CreateRoomDto) (*dto.ResponseRoo
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Error creating room")
 	}
 
+	s.publishToCloud(enum.LAYOUT_CREATE, room)
+
 	return &dto.ResponseRoomDto{
 		ID:        room.ID,
+		Guid:      room.Guid,
 		Name:      room.Name,
 		FloorID:   room.FloorID,
 		CreatedAt: room.CreatedAt,
@@ -53,6 +70,7 @@ func (s *RoomService) GetAllRooms() ([]dto.ResponseRoomDto, error) {
 	for _, room := range rooms {
 		result = append(result, dto.ResponseRoomDto{
 			ID:        room.ID,
+			Guid:      room.Guid,
 			Name:      room.Name,
 			FloorID:   room.FloorID,
 			CreatedAt: room.CreatedAt,
@@ -76,6 +94,7 @@ func (s *RoomService) GetRoomsByFloorID(floorID string) ([]dto.ResponseRoomDto,
 	for _, room := range rooms {
 		result = append(result, dto.ResponseRoomDto{
 			ID:        room.ID,
+			Guid:      room.Guid,
 			Name:      room.Name,
 			FloorID:   room.FloorID,
 			CreatedAt: room.CreatedAt,
@@ -96,6 +115,7 @@ func (s *RoomService) GetRoomByID(id string) (*dto.ResponseRoomDto, error) {
 
 	return &dto.ResponseRoomDto{
 		ID:        room.ID,
+		Guid:      room.Guid,
 		Name:      room.Name,
 		FloorID:   room.FloorID,
 		CreatedAt: room.CreatedAt,
@@ -120,8 +140,11 @@ func (s *RoomService) UpdateRoom(id string, updateDto *dto.UpdateRoomDto) (*dto.
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Error updating room")
 	}
 
+	s.publishToCloud(enum.LAYOUT_UPDATE, &room)
+
 	return &dto.ResponseRoomDto{
 		ID:        room.ID,
+		Guid:      room.Guid,
 		Name:      room.Name,
 		FloorID:   room.FloorID,
 		CreatedAt: room.CreatedAt,
@@ -142,5 +165,140 @@ func (s *RoomService) DeleteRoom(id string) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Error deleting room")
 	}
 
+	s.publishToCloud(enum.LAYOUT_DELETE, &room)
+
 	return nil
 }
+
+func (s *RoomService) ApplyCloudRoom(reqDto *dto.ReqCloudRoomDto) error {
+	var room model.Room
+
+	err := s.db.Where("guid = ?", reqDto.Guid).First(&room).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to find room %s: %w", reqDto.Guid, err)
+	}
+
+	found := err == nil
+
+	if reqDto.Action == enum.LAYOUT_DELETE {
+		if !found {
+			log.Infof("Room %s is already deleted ✅", reqDto.Guid)
+			return nil
+		}
+
+		if err := s.db.Delete(&room).Error; err != nil {
+			return fmt.Errorf("error deleting room %s: %w", reqDto.Guid, err)
+		}
+
+		log.Infof("Room successfully deleted from cloud: %s ✅", room.Name)
+
+		return nil
+	}
+
+	var floor model.Floor
+
+	if err := s.db.Where("guid = ?", reqDto.FloorGuid).First(&floor).Error; err != nil {
+		return fmt.Errorf("floor %s of room %s not found: %w", reqDto.FloorGuid, reqDto.Guid, err)
+	}
+
+	if !found {
+		room = model.Room{
+			Guid:      reqDto.Guid,
+			CreatedAt: time.Now().In(location),
+		}
+	}
+
+	room.Name = reqDto.Name
+	room.FloorID = floor.ID
+	room.UpdatedAt = time.Now().In(location)
+
+	if err := s.db.Omit("Floor").Save(&room).Error; err != nil {
+		return fmt.Errorf("error saving room %s: %w", reqDto.Guid, err)
+	}
+
+	log.Infof("Room successfully saved from cloud: %s ✅", room.Name)
+
+	return nil
+}
+
+func (s *RoomService) ControlRoom(ctx context.Context, id string, controlDto *dto.ReqBulkControlDto, wait bool) (*dto.ResponseBulkControlDto, error) {
+	var room model.Room
+
+	if err := s.controlDeviceService.CheckWait(wait); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.First(&room, id).Error; err != nil {
+		log.Errorf("Room not found: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusNotFound, "Room not found")
+	}
+
+	return s.controlRoom(ctx, &room, controlDto.Value, enum.COMMAND_SOURCE_LOCAL, wait)
+}
+
+func (s *RoomService) ControlCloudRoom(reqDto *dto.ReqCloudBulkControlDto) (*dto.ResponseBulkControlDto, error) {
+	var room model.Room
+
+	if err := s.db.Where("guid = ?", reqDto.Guid).First(&room).Error; err != nil {
+		log.Errorf("Room not found: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusNotFound, "Room not found")
+	}
+
+	return s.controlRoom(context.Background(), &room, reqDto.Value, enum.COMMAND_SOURCE_CLOUD, false)
+}
+
+func (s *RoomService) controlRoom(ctx context.Context, room *model.Room, value string, source enum.ECommandSource, wait bool) (*dto.ResponseBulkControlDto, error) {
+	var devices []model.Registration
+
+	if err := s.db.Where("room_id = ? AND type = ?", room.ID, enum.AKTUATOR).
+		Order("registrations.id ASC").
+		Find(&devices).Error; err != nil {
+		log.Errorf("Error getting aktuators of room: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error getting aktuators of room")
+	}
+
+	if len(devices) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Room has no aktuators")
+	}
+
+	response := s.controlDeviceService.ControlDevices(ctx, "room "+room.Name, devices, value, source, wait)
+
+	log.Infof("Room %s controlled with value %s: %d succeeded, %d failed ✅", room.Name, value, response.Succeeded, response.Failed)
+
+	return response, nil
+}
+
+func (s *RoomService) publishToCloud(action enum.ELayoutAction, room *model.Room) {
+	var floor model.Floor
+
+	if err := s.db.Select("guid").First(&floor, room.FloorID).Error; err != nil {
+		log.Warnf("Floor %d of room %s not found: %v", room.FloorID, room.Guid, err)
+	}
+
+	body, err := json.Marshal(dto.ResCloudRoomDto{
+		Action:    action,
+		MacServer: config.MAC_ADDRESS.GetValue(),
+		ID:        room.ID,
+		Guid:      room.Guid,
+		Name:      room.Name,
+		FloorID:   room.FloorID,
+		FloorGuid: floor.Guid,
+		CreatedAt: room.CreatedAt,
+		UpdatedAt: room.UpdatedAt,
+	})
+
+	if err != nil {
+		log.Errorf("Error marshaling JSON: %v 💥", err)
+		return
+	}
+
+	if err := s.outboxService.Enqueue(
+		config.RMQ_CLOUD_INSTANCE.GetValue(),
+		body,
+		config.ROOM_RES_CLOUD.GetValue(),
+		config.EXCHANGE_DIRECT.GetValue(),
+	); err != nil {
+		log.Errorf("Error queueing room %s to cloud: %v 💥", action, err)
+	}
+}
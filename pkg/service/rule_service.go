@@ -6,7 +6,6 @@ import (
 	"go/hioto/config"
 	"go/hioto/pkg/dto"
 	"go/hioto/pkg/enum"
-	messagebroker "go/hioto/pkg/handler/message_broker"
 	"go/hioto/pkg/model"
 	"math"
 	"time"
@@ -23,12 +22,14 @@ func init() {
 }
 
 type RuleService struct {
-	db *gorm.DB
+	db            *gorm.DB
+	outboxService *OutboxService
 }
 
-func NewRuleService(db *gorm.DB) *RuleService {
+func NewRuleService(db *gorm.DB, outboxService *OutboxService) *RuleService {
 	return &RuleService{
-		db: db,
+		db:            db,
+		outboxService: outboxService,
 	}
 }
 
@@ -44,24 +45,67 @@ func generateSensorPatterns(length int) []string {
 	return patterns
 }
 
-func (s *RuleService) CreateRules(createRuleDto *dto.CreateRuleDto) (responseRules []dto.ResponseRuleDto, err error) {
+func (s *RuleService) CreateRules(createRuleDto *dto.CreateRuleDto) ([]dto.ResponseRuleDto, error) {
+	return s.saveRules(createRuleDto, false, true)
+}
+
+func (s *RuleService) SaveCloudRules(createRuleDto *dto.CreateRuleDto, replace bool) ([]dto.ResponseRuleDto, error) {
+	return s.saveRules(createRuleDto, replace, false)
+}
+
+func (s *RuleService) saveRules(createRuleDto *dto.CreateRuleDto, replace, notifyCloud bool) (responseRules []dto.ResponseRuleDto, err error) {
 	if err = s.db.Where("guid = ?", createRuleDto.InputGuid).First(&model.Registration{}).Error; err != nil {
 		log.Errorf("Sensor is'nt found: %v 💥", err)
 		return nil, fiber.NewError(fiber.StatusNotFound, "The Sensor is not found")
 	}
 
-	for _, actuator := range createRuleDto.OutputGuid {
-		if err = s.db.Where("guid = ?", actuator).First(&model.Registration{}).Error; err != nil {
+	outputGuids := make([]string, len(createRuleDto.OutputGuid))
+	outputChannels := make([]int, len(createRuleDto.OutputGuid))
+
+	for i, address := range createRuleDto.OutputGuid {
+		var actuator model.Registration
+
+		if outputGuids[i], outputChannels[i], err = parseChannelAddress(address); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+
+		if err = s.db.Where("guid = ?", outputGuids[i]).First(&actuator).Error; err != nil {
 			log.Errorf("The actuator not found: %v 💥", err)
 			return nil, fiber.NewError(fiber.StatusNotFound, "The actuator is not found")
 		}
+
+		if err = validateChannel(&actuator, outputChannels[i]); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
 	}
 
 	length := len(createRuleDto.OutputGuid)
 	sensorPatterns := generateSensorPatterns(length)
 
+	tx := s.db.Begin()
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			log.Errorf("Transaction rollback due to panic: %v 💥", r)
+		} else if err != nil {
+			tx.Rollback()
+		} else if commitErr := tx.Commit().Error; commitErr != nil {
+			log.Errorf("Error committing transaction: %v 💥", commitErr)
+			tx.Rollback()
+			responseRules, err = nil, fiber.NewError(fiber.StatusBadRequest, "Error creating rule")
+		}
+	}()
+
+	if replace {
+		if err = tx.Where("input_guid = ?", createRuleDto.InputGuid).Delete(&model.RuleDevice{}).Error; err != nil {
+			log.Errorf("Error deleting previous rules: %v 💥", err)
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Error replacing rules")
+		}
+	}
+
 	for _, sensor := range sensorPatterns {
-		for i, actuator := range createRuleDto.OutputGuid {
+		for i, actuator := range outputGuids {
 			outputValue := '1'
 
 			if sensor[i] == '1' {
@@ -69,44 +113,52 @@ func (s *RuleService) CreateRules(createRuleDto *dto.CreateRuleDto) (responseRul
 			}
 
 			rule := &model.RuleDevice{
-				InputGuid:   createRuleDto.InputGuid,
-				InputValue:  sensor,
-				OutputGuid:  actuator,
-				OutputValue: string(outputValue),
-				CreatedAt:   time.Now().In(locations),
-				UpdatedAt:   time.Now().In(locations),
+				InputGuid:     createRuleDto.InputGuid,
+				InputValue:    sensor,
+				OutputGuid:    actuator,
+				OutputChannel: outputChannels[i],
+				OutputValue:   string(outputValue),
+				CreatedAt:     time.Now().In(locations),
+				UpdatedAt:     time.Now().In(locations),
 			}
 
-			if err = s.db.Create(rule).Error; err != nil {
+			if err = tx.Create(rule).Error; err != nil {
 				log.Errorf("Error creating rule: %v 💥", err)
 				return nil, fiber.NewError(fiber.StatusBadRequest, "Error creating rule")
 			}
 
 			responseRules = append(responseRules, dto.ResponseRuleDto{
-				MacServer:   config.MAC_ADDRESS.GetValue(),
-				InputGuid:   rule.InputGuid,
-				InputValue:  rule.InputValue,
-				OutputGuid:  rule.OutputGuid,
-				OutputValue: rule.OutputValue,
-				CreatedAt:   rule.CreatedAt.Format(time.RFC3339),
-				UpdatedAt:   rule.UpdatedAt.Format(time.RFC3339),
+				MacServer:     config.MAC_ADDRESS.GetValue(),
+				InputGuid:     rule.InputGuid,
+				InputValue:    rule.InputValue,
+				OutputGuid:    rule.OutputGuid,
+				OutputChannel: rule.OutputChannel,
+				OutputValue:   rule.OutputValue,
+				CreatedAt:     rule.CreatedAt.Format(time.RFC3339),
+				UpdatedAt:     rule.UpdatedAt.Format(time.RFC3339),
 			})
 		}
 	}
 
-	responseToJson, err := json.Marshal(responseRules)
+	if notifyCloud {
+		var responseToJson []byte
 
-	if err != nil {
-		log.Errorf("Failed to marshal response: %v", err)
-		return nil, fiber.NewError(fiber.StatusBadRequest, "Failed to marshal response")
-	}
+		if responseToJson, err = json.Marshal(responseRules); err != nil {
+			log.Errorf("Failed to marshal response: %v", err)
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Failed to marshal response")
+		}
 
-	messagebroker.PublishToRmq(
-		config.RMQ_CLOUD_INSTANCE.GetValue(),
-		responseToJson,
-		config.RULES_RESPONSE_QUEUE.GetValue(),
-		config.EXCHANGE_DIRECT.GetValue(),
-	)
+		if err = s.outboxService.EnqueueTx(
+			tx,
+			config.RMQ_CLOUD_INSTANCE.GetValue(),
+			responseToJson,
+			config.RULES_RESPONSE_QUEUE.GetValue(),
+			config.EXCHANGE_DIRECT.GetValue(),
+		); err != nil {
+			log.Errorf("Error queueing rules response to cloud: %v 💥", err)
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Error queueing rules response")
+		}
+	}
 
 	log.Info("Rule was created successfully ✅")
 
@@ -143,6 +195,7 @@ func (s *RuleService) GetAllRulesPagination(params *dto.GetRulesPagination) (*mo
 			r.input_value as sensor_input_value,
 			r.output_guid as guid_aktuator,
 			a.name as aktuator_name,
+			r.output_channel as aktuator_channel,
             r.output_value as aktuator_output_value,
             r.created_at,
             r.updated_at
@@ -195,13 +248,14 @@ func (s *RuleService) GetRulesByGuid(guid string) ([]dto.ResponseRuleDto, error)
 	var responseRules []dto.ResponseRuleDto
 	for _, rule := range rules {
 		responseRules = append(responseRules, dto.ResponseRuleDto{
-			MacServer:   config.MAC_ADDRESS.GetValue(),
-			InputGuid:   rule.InputGuid,
-			InputValue:  rule.InputValue,
-			OutputGuid:  rule.OutputGuid,
-			OutputValue: rule.OutputValue,
-			CreatedAt:   rule.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   rule.UpdatedAt.Format(time.RFC3339),
+			MacServer:     config.MAC_ADDRESS.GetValue(),
+			InputGuid:     rule.InputGuid,
+			InputValue:    rule.InputValue,
+			OutputGuid:    rule.OutputGuid,
+			OutputChannel: rule.OutputChannel,
+			OutputValue:   rule.OutputValue,
+			CreatedAt:     rule.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:     rule.UpdatedAt.Format(time.RFC3339),
 		})
 	}
 
@@ -225,3 +279,41 @@ func (s *RuleService) DeleteRulesByGuidSensor(guid string) error {
 
 	return nil
 }
+
+const (
+	RULE_ACTION_CREATE  = "create"
+	RULE_ACTION_REPLACE = "replace"
+	RULE_ACTION_DELETE  = "delete"
+)
+
+func (s *RuleService) PublishRuleResult(action, inputGuid string, rules []dto.ResponseRuleDto, cause error) error {
+	result := dto.ResponseRuleResultDto{
+		MacServer: config.MAC_ADDRESS.GetValue(),
+		Action:    action,
+		InputGuid: inputGuid,
+		Success:   cause == nil,
+		Rules:     rules,
+	}
+
+	if result.Rules == nil {
+		result.Rules = []dto.ResponseRuleDto{}
+	}
+
+	if cause != nil {
+		result.Error = cause.Error()
+	}
+
+	body, err := json.Marshal(result)
+
+	if err != nil {
+		log.Errorf("Failed to marshal rule result: %v", err)
+		return err
+	}
+
+	return s.outboxService.Enqueue(
+		config.RMQ_CLOUD_INSTANCE.GetValue(),
+		body,
+		config.RULES_RESULT_QUEUE.GetValue(),
+		config.EXCHANGE_DIRECT.GetValue(),
+	)
+}
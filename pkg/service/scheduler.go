@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type ScheduledJob func(ctx context.Context) error
+
+type scheduledEntry struct {
+	name     string
+	interval time.Duration
+	job      ScheduledJob
+}
+
+type Scheduler struct {
+	entries []scheduledEntry
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+func (s *Scheduler) Every(name string, interval time.Duration, job ScheduledJob) {
+	s.entries = append(s.entries, scheduledEntry{name: name, interval: interval, job: job})
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, entry := range s.entries {
+		go s.run(ctx, entry)
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, entry scheduledEntry) {
+	ticker := time.NewTicker(entry.interval)
+	defer ticker.Stop()
+
+	log.Infof("Scheduled job %s every %s ⏰", entry.name, entry.interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Warnf("Scheduled job %s stopped", entry.name)
+			return
+		case <-ticker.C:
+		}
+
+		if err := entry.job(ctx); err != nil {
+			log.Errorf("Scheduled job %s failed: %v 💥", entry.name, err)
+		}
+	}
+}
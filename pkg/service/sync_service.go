@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/hioto/config"
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/model"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSyncSnapshotQueue = "Sync_snapshot"
+	defaultSyncReportQueue   = "Sync_report"
+	syncPolicyReport         = "report"
+)
+
+type SyncService struct {
+	db            *gorm.DB
+	deviceService *DeviceService
+	outboxService *OutboxService
+	wake          chan struct{}
+	mu            sync.RWMutex
+	lastReport    *dto.SyncReportDto
+}
+
+func NewSyncService(db *gorm.DB, deviceService *DeviceService, outboxService *OutboxService) *SyncService {
+	return &SyncService{
+		db:            db,
+		deviceService: deviceService,
+		outboxService: outboxService,
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+func envOrDefault(key config.EnvKey, fallback string) string {
+	if value := key.GetValue(); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func itemDigest(value any) string {
+	body, _ := json.Marshal(value)
+	sum := sha256.Sum256(body)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func snapshotDigest(items map[string]string) string {
+	keys := make([]string, 0, len(items))
+
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	hash := sha256.New()
+
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", key, items[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func snapshotItems(devices []dto.SnapshotDeviceDto, rules []dto.SnapshotRuleDto, floors []dto.SnapshotFloorDto, rooms []dto.SnapshotRoomDto) map[string]string {
+	items := make(map[string]string, len(devices)+len(rules)+len(floors)+len(rooms))
+
+	for _, device := range devices {
+		items["device:"+device.Guid] = itemDigest(device)
+	}
+
+	for _, rule := range rules {
+		items[fmt.Sprintf("rule:%s:%s:%s", rule.InputGuid, rule.InputValue, channelAddress(rule.OutputGuid, rule.OutputChannel))] = itemDigest(rule)
+	}
+
+	for _, floor := range floors {
+		items["floor:"+floor.Guid] = itemDigest(floor)
+	}
+
+	for _, room := range rooms {
+		items["room:"+room.Guid] = itemDigest(room)
+	}
+
+	return items
+}
+
+func (s *SyncService) BuildSnapshot() (*dto.SnapshotDto, error) {
+	var (
+		devices []model.Registration
+		rules   []model.RuleDevice
+		floors  []model.Floor
+		rooms   []model.Room
+	)
+
+	if err := s.db.Preload("Room").Order("guid ASC").Find(&devices).Error; err != nil {
+		return nil, fmt.Errorf("failed to read devices: %w", err)
+	}
+
+	if err := s.db.Order("input_guid ASC, input_value ASC, output_guid ASC, output_channel ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+
+	if err := s.db.Order("id ASC").Find(&floors).Error; err != nil {
+		return nil, fmt.Errorf("failed to read floors: %w", err)
+	}
+
+	if err := s.db.Preload("Floor").Order("id ASC").Find(&rooms).Error; err != nil {
+		return nil, fmt.Errorf("failed to read rooms: %w", err)
+	}
+
+	snapshot := &dto.SnapshotDto{
+		MacServer:   config.MAC_ADDRESS.GetValue(),
+		GeneratedAt: time.Now().In(location),
+		Devices:     []dto.SnapshotDeviceDto{},
+		Rules:       []dto.SnapshotRuleDto{},
+		Floors:      []dto.SnapshotFloorDto{},
+		Rooms:       []dto.SnapshotRoomDto{},
+	}
+
+	for _, device := range devices {
+		var roomGuid *string
+
+		if device.Room != nil {
+			roomGuid = &device.Room.Guid
+		}
+
+		snapshot.Devices = append(snapshot.Devices, dto.SnapshotDeviceDto{
+			Guid:     device.Guid,
+			Mac:      device.Mac,
+			Type:     device.Type,
+			Quantity: device.Quantity,
+			Name:     device.Name,
+			Version:  device.Version,
+			Minor:    device.Minor,
+			RoomGuid: roomGuid,
+		})
+	}
+
+	for _, rule := range rules {
+		snapshot.Rules = append(snapshot.Rules, dto.SnapshotRuleDto{
+			InputGuid:     rule.InputGuid,
+			InputValue:    rule.InputValue,
+			OutputGuid:    rule.OutputGuid,
+			OutputChannel: rule.OutputChannel,
+			OutputValue:   rule.OutputValue,
+		})
+	}
+
+	for _, floor := range floors {
+		snapshot.Floors = append(snapshot.Floors, dto.SnapshotFloorDto{Guid: floor.Guid, Name: floor.Name})
+	}
+
+	for _, room := range rooms {
+		snapshot.Rooms = append(snapshot.Rooms, dto.SnapshotRoomDto{Guid: room.Guid, Name: room.Name, FloorGuid: room.Floor.Guid})
+	}
+
+	snapshot.Items = snapshotItems(snapshot.Devices, snapshot.Rules, snapshot.Floors, snapshot.Rooms)
+	snapshot.Digest = snapshotDigest(snapshot.Items)
+
+	return snapshot, nil
+}
+
+func (s *SyncService) PublishSnapshot() (*dto.SnapshotDto, error) {
+	snapshot, err := s.BuildSnapshot()
+
+	if err != nil {
+		log.Errorf("Error building snapshot: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Error building snapshot")
+	}
+
+	body, err := json.Marshal(snapshot)
+
+	if err != nil {
+		log.Errorf("Error marshaling snapshot: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Error marshaling snapshot")
+	}
+
+	if err := s.outboxService.Enqueue(
+		config.RMQ_CLOUD_INSTANCE.GetValue(),
+		body,
+		envOrDefault(config.SYNC_SNAPSHOT_QUEUE, defaultSyncSnapshotQueue),
+		config.EXCHANGE_DIRECT.GetValue(),
+	); err != nil {
+		log.Errorf("Error queueing snapshot to cloud: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Error queueing snapshot to cloud")
+	}
+
+	log.Infof("Snapshot %s queued for cloud with %d items 🔁", snapshot.Digest[:12], len(snapshot.Items))
+
+	return snapshot, nil
+}
+
+func (s *SyncService) Reconcile(request *dto.ReqSyncDto) (*dto.SyncReportDto, error) {
+	snapshot, err := s.BuildSnapshot()
+
+	if err != nil {
+		return nil, err
+	}
+
+	cloudItems := request.Items
+
+	if len(cloudItems) == 0 && (request.Devices != nil || request.Rules != nil || request.Floors != nil || request.Rooms != nil) {
+		cloudItems = snapshotItems(request.Devices, request.Rules, request.Floors, request.Rooms)
+	}
+
+	cloudDigest := request.Digest
+
+	if cloudDigest == "" && cloudItems != nil {
+		cloudDigest = snapshotDigest(cloudItems)
+	}
+
+	report := &dto.SyncReportDto{
+		MacServer:    snapshot.MacServer,
+		Digest:       snapshot.Digest,
+		CloudDigest:  cloudDigest,
+		InSync:       cloudDigest == snapshot.Digest,
+		LocalOnly:    []string{},
+		CloudOnly:    []string{},
+		Changed:      []string{},
+		Applied:      []string{},
+		Conflicts:    []string{},
+		ReconciledAt: time.Now().In(location),
+	}
+
+	if !report.InSync && cloudItems == nil {
+		if _, err := s.PublishSnapshot(); err != nil {
+			return nil, err
+		}
+
+		report.Conflicts = append(report.Conflicts, "digest mismatch, full snapshot sent")
+
+		return s.finishReport(report)
+	}
+
+	if report.InSync {
+		return s.finishReport(report)
+	}
+
+	for key, hash := range snapshot.Items {
+		cloudHash, ok := cloudItems[key]
+
+		switch {
+		case !ok:
+			report.LocalOnly = append(report.LocalOnly, key)
+		case cloudHash != hash:
+			report.Changed = append(report.Changed, key)
+		}
+	}
+
+	for key := range cloudItems {
+		if _, ok := snapshot.Items[key]; !ok {
+			report.CloudOnly = append(report.CloudOnly, key)
+		}
+	}
+
+	sort.Strings(report.LocalOnly)
+	sort.Strings(report.CloudOnly)
+	sort.Strings(report.Changed)
+
+	applyChanges := !strings.EqualFold(config.SYNC_POLICY.GetValue(), syncPolicyReport)
+
+	s.resolve(report, report.LocalOnly, config.REGISTER_RES_CLOUD.GetValue(), applyChanges)
+	s.resolve(report, report.Changed, config.UPDATE_RES_CLOUD.GetValue(), applyChanges)
+	s.resolve(report, report.CloudOnly, config.DELETE_RES_CLOUD.GetValue(), applyChanges)
+
+	return s.finishReport(report)
+}
+
+func (s *SyncService) resolve(report *dto.SyncReportDto, keys []string, queue string, applyChanges bool) {
+	for _, key := range keys {
+		guid, isDevice := strings.CutPrefix(key, "device:")
+
+		if !isDevice || !applyChanges {
+			report.Conflicts = append(report.Conflicts, key)
+			continue
+		}
+
+		if err := s.publishDevice(guid, queue); err != nil {
+			log.Errorf("Error resolving %s: %v 💥", key, err)
+			report.Conflicts = append(report.Conflicts, key)
+			continue
+		}
+
+		report.Applied = append(report.Applied, key)
+	}
+}
+
+func (s *SyncService) publishDevice(guid, queue string) error {
+	var body []byte
+	var err error
+
+	if queue == config.DELETE_RES_CLOUD.GetValue() {
+		body, err = json.Marshal(dto.ReqDeleteDeviceToCloudDto{
+			Guid:      guid,
+			MacServer: config.MAC_ADDRESS.GetValue(),
+		})
+	} else {
+		device, findErr := s.deviceService.GetDeviceByGuid(guid)
+
+		if findErr != nil {
+			return findErr
+		}
+
+		body, err = json.Marshal(dto.ResCloudDeviceDto{
+			ResponseDeviceDetailDto: *device,
+			MacServer:               config.MAC_ADDRESS.GetValue(),
+		})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return s.outboxService.Enqueue(
+		config.RMQ_CLOUD_INSTANCE.GetValue(),
+		body,
+		queue,
+		config.EXCHANGE_DIRECT.GetValue(),
+	)
+}
+
+func (s *SyncService) finishReport(report *dto.SyncReportDto) (*dto.SyncReportDto, error) {
+	s.mu.Lock()
+	s.lastReport = report
+	s.mu.Unlock()
+
+	body, err := json.Marshal(report)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sync report: %w", err)
+	}
+
+	if err := s.outboxService.Enqueue(
+		config.RMQ_CLOUD_INSTANCE.GetValue(),
+		body,
+		envOrDefault(config.SYNC_REPORT_QUEUE, defaultSyncReportQueue),
+		config.EXCHANGE_DIRECT.GetValue(),
+	); err != nil {
+		return nil, fmt.Errorf("failed to queue sync report: %w", err)
+	}
+
+	log.Infof(
+		"Reconciliation finished, in sync: %t, applied: %d, conflicts: %d 🔁",
+		report.InSync, len(report.Applied), len(report.Conflicts),
+	)
+
+	return report, nil
+}
+
+func (s *SyncService) GetLastReport() *dto.SyncReportDto {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lastReport
+}
+
+func (s *SyncService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *SyncService) StartReconciler(ctx context.Context) {
+	config.OnConnectionStateChange(func(kind, instanceName string, state config.ConnectionState) {
+		if kind == "rmq" && instanceName == config.RMQ_CLOUD_INSTANCE.GetValue() && state == config.STATE_CONNECTED {
+			s.notify()
+		}
+	})
+
+	if config.IsCloudOnline() {
+		s.notify()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		}
+
+		if _, err := s.PublishSnapshot(); err != nil {
+			log.Errorf("Error publishing snapshot on reconnect: %v 💥", err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"go/hioto/pkg/dto"
+	"go/hioto/pkg/enum"
+	"go/hioto/pkg/model"
+	"go/hioto/pkg/schema"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTelemetryRange  = 24 * time.Hour
+	defaultTelemetryBucket = time.Hour
+	maxTelemetryBuckets    = 1000
+)
+
+type TelemetryService struct {
+	db *gorm.DB
+}
+
+func NewTelemetryService(db *gorm.DB) *TelemetryService {
+	return &TelemetryService{db: db}
+}
+
+func telemetryPoints(histories []model.MonitoringHistory, readings []schema.Reading) []model.TelemetryPoint {
+	points := make([]model.TelemetryPoint, 0, len(readings))
+
+	for i, reading := range readings {
+		if reading.Number == nil || i >= len(histories) {
+			continue
+		}
+
+		historyID := histories[i].ID
+
+		points = append(points, model.TelemetryPoint{
+			DeviceGuid: histories[i].DeviceGuid,
+			Metric:     reading.Metric,
+			Value:      *reading.Number,
+			Unit:       reading.Unit,
+			Quality:    enum.TELEMETRY_GOOD,
+			HistoryID:  &historyID,
+			Time:       histories[i].Time,
+		})
+	}
+
+	return points
+}
+
+func telemetryRange(from, to string) (time.Time, time.Time, error) {
+	end := time.Now().In(location)
+	start := end.Add(-defaultTelemetryRange)
+
+	if to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return start, end, fiber.NewError(fiber.StatusBadRequest, "Query to must be RFC3339")
+		}
+
+		end = parsed.In(location)
+		start = end.Add(-defaultTelemetryRange)
+	}
+
+	if from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return start, end, fiber.NewError(fiber.StatusBadRequest, "Query from must be RFC3339")
+		}
+
+		start = parsed.In(location)
+	}
+
+	if !start.Before(end) {
+		return start, end, fiber.NewError(fiber.StatusBadRequest, "Query from must be before to")
+	}
+
+	return start, end, nil
+}
+
+func (s *TelemetryService) GetStats(guid, metric, from, to string) ([]dto.ResponseTelemetryStatsDto, error) {
+	start, end, err := telemetryRange(from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.Model(&model.TelemetryPoint{}).
+		Select("metric, unit, COUNT(*) AS count, AVG(value) AS avg, MIN(value) AS min, MAX(value) AS max").
+		Where("device_guid = ? AND time >= ? AND time < ?", guid, start, end).
+		Group("metric, unit").
+		Order("metric ASC")
+
+	if metric != "" {
+		query = query.Where("metric = ?", metric)
+	}
+
+	var result []dto.ResponseTelemetryStatsDto = []dto.ResponseTelemetryStatsDto{}
+
+	if err := query.Scan(&result).Error; err != nil {
+		log.Errorf("Error getting telemetry stats: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Error getting telemetry stats")
+	}
+
+	for i := range result {
+		result[i].From = start
+		result[i].To = end
+	}
+
+	return result, nil
+}
+
+func (s *TelemetryService) GetSeries(guid, metric, from, to, bucket string) (*dto.ResponseTelemetrySeriesDto, error) {
+	if metric == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Query metric is required")
+	}
+
+	start, end, err := telemetryRange(from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bucketSize := defaultTelemetryBucket
+
+	if bucket != "" {
+		bucketSize, err = time.ParseDuration(bucket)
+
+		if err != nil || bucketSize <= 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Query bucket must be a duration like 15m or 1h")
+		}
+	}
+
+	if end.Sub(start)/bucketSize > maxTelemetryBuckets {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Too many buckets, use a larger bucket or a shorter range")
+	}
+
+	var points []model.TelemetryPoint
+
+	if err := s.db.Where("device_guid = ? AND metric = ? AND time >= ? AND time < ?", guid, metric, start, end).
+		Order("time ASC").
+		Find(&points).Error; err != nil {
+		log.Errorf("Error getting telemetry series: %v 💥", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Error getting telemetry series")
+	}
+
+	series := &dto.ResponseTelemetrySeriesDto{
+		Guid:    guid,
+		Metric:  metric,
+		Bucket:  bucketSize.String(),
+		From:    start,
+		To:      end,
+		Buckets: []dto.ResponseTelemetryBucketDto{},
+	}
+
+	for _, point := range points {
+		series.Unit = point.Unit
+		bucketStart := start.Add(point.Time.Sub(start) / bucketSize * bucketSize)
+		last := len(series.Buckets) - 1
+
+		if last < 0 || !series.Buckets[last].Time.Equal(bucketStart) {
+			series.Buckets = append(series.Buckets, dto.ResponseTelemetryBucketDto{
+				Time: bucketStart,
+				Min:  math.Inf(1),
+				Max:  math.Inf(-1),
+			})
+			last++
+		}
+
+		current := &series.Buckets[last]
+		current.Avg = (current.Avg*float64(current.Count) + point.Value) / float64(current.Count+1)
+		current.Count++
+		current.Min = math.Min(current.Min, point.Value)
+		current.Max = math.Max(current.Max, point.Value)
+	}
+
+	return series, nil
+}
@@ -1,9 +1,16 @@
 package utils
 
 import (
+	"go/hioto/pkg/codec"
+	"go/hioto/pkg/enum"
 	"go/hioto/pkg/model"
+	"go/hioto/pkg/schema"
+	"time"
 
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func AutoMigrateDb(db *gorm.DB) {
@@ -14,4 +21,132 @@ func AutoMigrateDb(db *gorm.DB) {
 	db.AutoMigrate(&model.Log{})
 	db.AutoMigrate(&model.LogAktuator{})
 	db.AutoMigrate(&model.MonitoringHistory{})
+	db.AutoMigrate(&model.Outbox{})
+	db.AutoMigrate(&model.QuarantinedMessage{})
+	db.AutoMigrate(&model.ProcessedMessage{})
+	db.AutoMigrate(&model.AktuatorCommand{})
+	db.AutoMigrate(&model.AktuatorChannel{})
+	db.AutoMigrate(&model.DeviceGroup{})
+	db.AutoMigrate(&model.ExportCursor{})
+	db.AutoMigrate(&model.TelemetryPoint{})
+	db.AutoMigrate(&model.SchemaMigration{})
+
+	backfillGuids(db, &model.Floor{})
+	backfillGuids(db, &model.Room{})
+
+	runOnce(db, "telemetry_backfill", backfillTelemetry)
+}
+
+func runOnce(db *gorm.DB, name string, migration func(db *gorm.DB) error) {
+	var count int64
+
+	if err := db.Model(&model.SchemaMigration{}).Where("name = ?", name).Count(&count).Error; err != nil || count > 0 {
+		return
+	}
+
+	if err := migration(db); err != nil {
+		log.Errorf("Migration %s failed: %v 💥", name, err)
+		return
+	}
+
+	if err := db.Create(&model.SchemaMigration{Name: name, AppliedAt: time.Now()}).Error; err != nil {
+		log.Errorf("Error recording migration %s: %v 💥", name, err)
+		return
+	}
+
+	log.Infof("Migration %s applied ✅", name)
+}
+
+func backfillTelemetry(db *gorm.DB) error {
+	const batchSize = 500
+
+	var lastID uint
+
+	for {
+		var histories []model.MonitoringHistory
+
+		if err := db.Where("id > ?", lastID).Order("id ASC").Limit(batchSize).Find(&histories).Error; err != nil {
+			return err
+		}
+
+		if len(histories) == 0 {
+			return nil
+		}
+
+		points := make([]model.TelemetryPoint, 0, len(histories))
+
+		for _, history := range histories {
+			if point, ok := historyPoint(history); ok {
+				points = append(points, point)
+			}
+		}
+
+		if len(points) > 0 {
+			if err := db.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "history_id"}},
+				DoNothing: true,
+			}).Create(&points).Error; err != nil {
+				return err
+			}
+		}
+
+		lastID = histories[len(histories)-1].ID
+	}
+}
+
+func historyPoint(history model.MonitoringHistory) (model.TelemetryPoint, bool) {
+	historyID := history.ID
+	point := model.TelemetryPoint{
+		DeviceGuid: history.DeviceGuid,
+		HistoryID:  &historyID,
+		Time:       history.Time,
+	}
+
+	readings, err := schema.Parse(history.DeviceType, []codec.Reading{{Name: history.Name, Value: history.Value, Unit: history.Unit}})
+
+	if err == nil && readings[0].Number != nil {
+		point.Metric = readings[0].Metric
+		point.Value = *readings[0].Number
+		point.Unit = readings[0].Unit
+		point.Quality = enum.TELEMETRY_GOOD
+
+		return point, true
+	}
+
+	number, unit, ok := schema.ParseNumber(history.Value)
+
+	if !ok || len(unit) > 16 {
+		return point, false
+	}
+
+	if unit == "" {
+		unit = history.Unit
+	}
+
+	point.Metric = history.Name
+
+	if point.Metric == "" {
+		point.Metric = "value"
+	}
+
+	point.Value = number
+	point.Unit = unit
+	point.Quality = enum.TELEMETRY_UNCERTAIN
+
+	return point, true
+}
+
+func backfillGuids(db *gorm.DB, table any) {
+	var ids []uint
+
+	if err := db.Model(table).Where("guid IS NULL OR guid = ''").Pluck("id", &ids).Error; err != nil {
+		log.Errorf("Error reading rows without guid: %v 💥", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := db.Model(table).Where("id = ?", id).Update("guid", uuid.NewString()).Error; err != nil {
+			log.Errorf("Error backfilling guid: %v 💥", err)
+		}
+	}
 }